@@ -7,15 +7,19 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-	"time"
 
-	gogithub "github.com/google/go-github/v39/github"
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/gitlab"
+	llm "github.com/lucasmbaia/power-actions/core/openai"
 	"github.com/lucasmbaia/power-actions/services"
 	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 // CommitData represents the data of a single git commit
@@ -26,97 +30,134 @@ type CommitData struct {
 	Diffs   map[string]string // Maps file names to their diffs
 }
 
-var logger *zap.SugaredLogger
+var logger *zap.Logger
 var openAIClient *openai.Client
 
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create",
-	Short: "Automate creation of a pull request on GitHub for current branch changes",
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Automate creation of a pull request on GitHub, or a merge request on GitLab, for current branch changes",
+	RunE: func(cmd *cobra.Command, args []string) error {
 
-		atom := zap.NewAtomicLevel()
-		var level zapcore.Level
-		err := level.UnmarshalText([]byte(logLevel))
-		if err != nil {
-			fmt.Printf("Error setting log level: %s\n", err.Error())
-			return
-		}
-		atom.SetLevel(level)
-
-		cfg, _ := configureLogger()
-		logger = zap.New(zapcore.NewCore(
-			zapcore.NewJSONEncoder(*cfg),
-			zapcore.Lock(os.Stdout),
-			atom,
-		)).Sugar()
-		defer logger.Sync()
+		logger = config.EnvSingletons.Logger
 
 		// Initialize OpenAI client
 		openAIClient = openai.NewClient(viper.GetString("OPENAI_KEY"))
-		gitHubClient := services.NewGitHubClient(viper.GetString("GITHUB_KEY"))
-
 		gitRepoInfo, err := services.GetGitRepoInfo()
 		if err != nil {
-			logger.Error("Error getting git repository info", zap.Error(err))
-			return
+			return failure.Errorf(failure.Config, "getting the git repository info: %w", err)
+		}
+
+		host, err := newCodeHost(gitRepoInfo)
+		if err != nil {
+			return failure.Errorf(failure.Config, "creating the code host client: %w", err)
+		}
+
+		if err = config.LoadPolicy(); err != nil {
+			return err
+		}
+
+		if err = config.EnvConfig.Policy.Allow(gitRepoInfo.RepositoryPath, llm.Provider); err != nil {
+			return failure.Wrap(failure.Config, err)
 		}
 
 		commits, err := getCommits(gitRepoInfo.CurrentBranch, gitRepoInfo.PrincipalBranch)
 		if err != nil {
-			logger.Error("Error retrieving commits", zap.Error(err))
-			return
+			return failure.Wrap(failure.Config, err)
+		}
+
+		pullRequest := commitsContent(commits)
+		withheld := config.EnvConfig.Policy.Apply(&pullRequest)
+		if !withheld.Empty() {
+			logger.Info("Withheld content from the model by the data-governance policy",
+				zap.String("repository", gitRepoInfo.RepositoryPath),
+				zap.String("withheld", withheld.String()),
+			)
+		}
+		if len(withheld.Leaks) > 0 {
+			logger.Warn("Redacted the secrets added by the branch", zap.Int("count", len(withheld.Leaks)))
 		}
 
 		summaries := make([]string, 0, len(commits))
-		for _, commit := range commits {
-			summary := processSingleCommit(commit)
+		for _, commit := range pullRequest.Commits {
+			summary, err := processSingleCommit(commit)
+			if err != nil {
+				return err
+			}
 			if summary != "" {
 				summaries = append(summaries, summary)
 			}
 		}
 
 		if len(summaries) == 0 {
-			logger.Warn("No valid data to generate PR title and description.")
-			return
+			return failure.Errorf(failure.Config, "no changes of %s ahead of %s to describe the pull request with", gitRepoInfo.CurrentBranch, gitRepoInfo.PrincipalBranch)
 		}
 
 		finalPrompt := createFinalPrompt(summaries)
 		prInfo, err := generatePRTitleAndDescription(finalPrompt)
-
 		if err != nil {
-			logger.Error("Error generating PR title and description", err)
-			return
+			return err
 		}
 
 		// Create a new pull request
-		newPullRequest := &gogithub.NewPullRequest{
-			Title: gogithub.String(prInfo.Title),
-			Body:  gogithub.String(prInfo.Description),
-			Base:  gogithub.String(gitRepoInfo.PrincipalBranch),
-			Head:  gogithub.String(gitRepoInfo.CurrentBranch),
+		newPullRequest := github.NewPullRequest{
+			Owner:       gitRepoInfo.RepositoryOwner,
+			Repo:        gitRepoInfo.RepositoryName,
+			Title:       prInfo.Title,
+			Description: prInfo.Description,
+			Base:        gitRepoInfo.PrincipalBranch,
+			Head:        gitRepoInfo.CurrentBranch,
 		}
 
-		// Create a pull request
-		pr, resp, err := gitHubClient.Client.PullRequests.Create(context.Background(), gitRepoInfo.RepositoryOwner, gitRepoInfo.RepositoryName, newPullRequest)
+		// Projects of GitLab can be nested in subgroups
+		if i := strings.LastIndex(gitRepoInfo.RepositoryPath, "/"); i != -1 {
+			newPullRequest.Owner = gitRepoInfo.RepositoryPath[:i]
+		}
 
+		// Create a pull request
+		url, err := host.CreatePullRequest(newPullRequest)
 		if err != nil {
-			logger.Error("Error creating pull request", zap.Error(err))
-			return
+			return failure.Errorf(failure.CodeHost, "creating the pull request: %w", err)
 		}
 
-		if resp.StatusCode != 201 {
-			logger.Error("Error creating pull request", zap.Any("response", resp))
-			return
-		}
+		fmt.Printf("Pull request created successfully: %s\n", url)
 
-		fmt.Printf("Pull request created successfully: %s\n", pr.GetHTMLURL())
+		return nil
 	},
 }
 
+// newCodeHost returns the client of the code host of the origin remote. It is
+// GitLab when CODE_HOST says so, or when it is unset and the host of the
+// remote has gitlab in its name, and GitHub otherwise.
+func newCodeHost(info *services.GitRepoInfo) (host codehost.Host, err error) {
+	name, err := config.CodeHostName()
+	if err != nil {
+		return
+	}
+
+	if os.Getenv("CODE_HOST") == "" && strings.Contains(strings.ToLower(info.Host), "gitlab") {
+		name = codehost.GitLab
+	}
+
+	if name == codehost.GitLab {
+		gitlabClient, err := gitlab.NewClient(config.GitlabConfig(viper.GetString("GITLAB_KEY"), "https://"+info.Host))
+		return &gitlabClient, err
+	}
+
+	// Enterprise hosts are detected from the remote, unless GITHUB_API_URL says otherwise
+	githubConfig, err := config.GithubConfig(viper.GetString("GITHUB_KEY"), github.EnterpriseURL(info.Host))
+	if err != nil {
+		return
+	}
+
+	githubClient, err := github.NewClient(githubConfig)
+
+	return &githubClient, err
+}
+
 // processSingleCommit sends a single commit to the OpenAI API and returns a generated summary
-func processSingleCommit(commit CommitData) string {
-	fmt.Printf("Processing commit: %s\n", commit.ID)
+func processSingleCommit(commit content.Commit) (string, error) {
+	logger.Info("Processing commit", zap.String("commit", commit.SHA))
 
 	prompt := formatPromptForCommit(commit)
 	resp, err := openAIClient.CreateChatCompletion(
@@ -132,10 +173,12 @@ func processSingleCommit(commit CommitData) string {
 		},
 	)
 	if err != nil {
-		logger.Error("ChatCompletion error", zap.Error(err))
-		return ""
+		return "", failure.Errorf(failure.LLM, "summarizing commit %s: %w", commit.SHA, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", failure.Errorf(failure.LLM, "summarizing commit %s: the model returned no choices", commit.SHA)
 	}
-	return resp.Choices[0].Message.Content
+	return resp.Choices[0].Message.Content, nil
 }
 
 // createFinalPrompt aggregates all summaries into a final prompt for the PR title and description
@@ -163,8 +206,10 @@ func generatePRTitleAndDescription(prompt string) (services.PRInfo, error) {
 		},
 	)
 	if err != nil {
-		fmt.Printf("ChatCompletion error: %v\n", err)
-		return services.PRInfo{}, err
+		return services.PRInfo{}, failure.Errorf(failure.LLM, "generating the title and the description: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return services.PRInfo{}, failure.Errorf(failure.LLM, "generating the title and the description: the model returned no choices")
 	}
 
 	// JSON string
@@ -179,21 +224,47 @@ func generatePRTitleAndDescription(prompt string) (services.PRInfo, error) {
 	// Unmarshal the JSON into the struct
 	err = json.Unmarshal([]byte(jsonData), &prInfo)
 	if err != nil {
-		fmt.Println("Error unmarshalling JSON:", err)
-		return services.PRInfo{}, err
+		return services.PRInfo{}, failure.Errorf(failure.Parse, "parsing the title and the description: %w", err)
 	}
 
 	return prInfo, nil
 }
 
 // formatPromptForCommit creates a prompt for a single commit
-func formatPromptForCommit(commit CommitData) string {
-	diffs := make([]string, 0, len(commit.Diffs))
-	for _, diff := range commit.Diffs {
+func formatPromptForCommit(commit content.Commit) string {
+	files := make([]string, 0, len(commit.Files))
+	diffs := make([]string, 0, len(commit.Files))
+	for _, file := range commit.Files {
+		files = append(files, file.Filename)
+
+		diff := fmt.Sprintf("diff --git a/%s b/%s", file.Filename, file.Filename)
+		for _, hunk := range file.Hunks {
+			diff += "\n" + hunk.Header + "\n" + hunk.Body
+		}
 		diffs = append(diffs, diff)
 	}
 	return fmt.Sprintf("Analyze the following commit and summarize its impact and changes:\nCommit ID: %s\nMessage: %s\nFiles Changed: %s\nDiffs:\n%s",
-		commit.ID, commit.Message, strings.Join(commit.Files, ", "), strings.Join(diffs, "\n"))
+		commit.SHA, commit.Message, strings.Join(files, ", "), strings.Join(diffs, "\n"))
+}
+
+// commitsContent converts the commits into the content model, so they go
+// through the same data-governance policy and redaction as the pull requests
+// that are reviewed before they are sent to the model.
+func commitsContent(commits []CommitData) (pr content.PullRequest) {
+	for _, commit := range commits {
+		cc := content.Commit{SHA: commit.ID, Message: commit.Message}
+
+		for _, file := range commit.Files {
+			cc.Files = append(cc.Files, content.File{
+				Filename: file,
+				Hunks:    content.ParsePatch(commit.Diffs[file]),
+			})
+		}
+
+		pr.Commits = append(pr.Commits, cc)
+	}
+
+	return
 }
 
 func getCommits(currentBranch string, mainBranch string) ([]CommitData, error) {
@@ -241,6 +312,7 @@ func getCommits(currentBranch string, mainBranch string) ([]CommitData, error) {
 
 var openAIKey string
 var gitHubKey string
+var gitLabKey string
 var logLevel string
 
 func init() {
@@ -251,7 +323,7 @@ func init() {
 	viper.SetEnvPrefix("POWERPR") // Set a prefix for environment variables to avoid conflicts
 	viper.BindEnv("OPENAI_KEY")   // Bind the environment variable to a key
 	viper.BindEnv("GITHUB_KEY")   // Bind the environment variable to a key
-	viper.BindEnv("LOG_LEVEL")    // Bind the environment variable to a key
+	viper.BindEnv("GITLAB_KEY")   // Bind the environment variable to a key
 
 	// Optionally set a default in case the env isn't set
 	//viper.SetDefault("KEY", "your-default-key")
@@ -261,16 +333,10 @@ func init() {
 	viper.BindPFlag("OPENAI_KEY", createCmd.Flags().Lookup("openAIKey"))
 	createCmd.Flags().StringVarP(&gitHubKey, "gitHubKey", "g", "", "GitHub key")
 	viper.BindPFlag("GITHUB_KEY", createCmd.Flags().Lookup("gitHubKey"))
+	createCmd.Flags().StringVar(&gitLabKey, "gitLabKey", "", "GitLab key")
+	viper.BindPFlag("GITLAB_KEY", createCmd.Flags().Lookup("gitLabKey"))
 
-	createCmd.Flags().StringVarP(&logLevel, "logLevel", "l", "debug", "Log level (debug, info, warn, error, dpanic, panic, fatal)")
-	viper.BindPFlag("LOG_LEVEL", createCmd.Flags().Lookup("logLevel"))
-
-}
-
-func configureLogger() (*zapcore.EncoderConfig, error) {
-	cfg := zap.NewProductionEncoderConfig()
-	cfg.TimeKey = "timestamp"
-	cfg.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC3339)
+	createCmd.Flags().StringVarP(&logLevel, "logLevel", "l", "info", "Log level (debug, info, warn, error)")
+	createCmd.Flags().MarkDeprecated("logLevel", "use --log-level instead")
 
-	return &cfg, nil
 }
@@ -22,30 +22,83 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"fmt"
+	"strings"
 
 	"github.com/lucasmbaia/power-actions/config"
 	"github.com/lucasmbaia/power-actions/core"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
 	"github.com/spf13/cobra"
 )
 
 // reviewCmd represents the review command
 var reviewCmd = &cobra.Command{
 	Use:   "review",
-	Short: "Automate PR reviews on GitHub",
-	Run: func(cmd *cobra.Command, args []string) {
-		config.LoadSingletons()
+	Short: "Automate PR reviews on GitHub and GitLab",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = config.LoadSingletons(); err != nil {
+			return
+		}
+		config.EnvConfig.Outputs = outputs
 
-		err := core.Run()
-		if err != nil {
-			fmt.Printf("Error to review the PR: %s\n", err.Error())
+		config.EnvConfig.DryRun = dryRun
+
+		if config.EnvConfig.FailOn, err = parseFailOn(failOn); err != nil {
 			return
 		}
+
+		if noCache {
+			config.EnvSingletons.OpenaiClient.SetCache(nil)
+		}
+
+		if err = config.LoadPullRequest(); err != nil {
+			return
+		}
+
+		if err = config.LoadProfiles(); err != nil {
+			return
+		}
+
+		if err = config.LoadPolicy(); err != nil {
+			return
+		}
+
+		if err = core.ValidateOutputs(outputs); err != nil {
+			return
+		}
+
+		return core.Run()
 	},
 }
 
+var (
+	outputs []string
+	noCache bool
+	dryRun  bool
+	failOn  string
+)
+
+// parseFailOn parses the severity of --fail-on, where "none" and an empty
+// value never fail the review.
+func parseFailOn(value string) (severity github.Severity, err error) {
+	if value == "" || strings.EqualFold(value, "none") {
+		return "", nil
+	}
+
+	if severity, err = github.ParseSeverity(value); err != nil {
+		return "", failure.Errorf(failure.Config, "--fail-on: %w", err)
+	}
+
+	return
+}
+
 func init() {
 	rootCmd.AddCommand(reviewCmd)
+
+	reviewCmd.Flags().StringSliceVar(&outputs, "output", []string{core.OutputReview}, "Where to publish the findings: review (pull request review comments), check (check run annotations) or sarif=<path> (SARIF 2.1.0 file). Can be repeated")
+	reviewCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the findings, and the verdicts of their verification, as JSON instead of publishing them")
+	reviewCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with code 6 when a finding with a comment, as posted, is at least this severe: info, low, medium, high, critical or none")
+	reviewCmd.Flags().BoolVar(&noCache, "no-cache", false, "Ask the model again instead of reusing the answers cached in LLM_CACHE_DIR")
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -22,14 +22,20 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"fmt"
 	"os"
 
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/logging"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
-var cfgFile string
+var (
+	cfgFile     string
+	cfgFileUsed string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -38,15 +44,56 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// create had its own --logLevel flag before every command shared one
+		if flag := cmd.Flags().Lookup("logLevel"); flag != nil && flag.Changed {
+			viper.Set("LOG_LEVEL", flag.Value.String())
+		}
+
+		if err := config.LoadLogger(); err != nil {
+			return failure.Wrap(failure.Config, err)
+		}
+
+		if cfgFileUsed != "" {
+			config.EnvSingletons.Logger.Info("Using config file", zap.String("file", cfgFileUsed))
+		}
+
+		// From now on, the errors of the command are logged by Execute,
+		// redacted, instead of being printed with the usage
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		config.EnvSingletons.Logger.Sync()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+//
+// A failing command exits with the code of its kind of failure, listed in the
+// failure package, and 1 for the others.
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+
+	code := failure.ExitCode(err)
+
+	if cmd.SilenceErrors {
+		fields := []zap.Field{zap.String("command", cmd.Name()), zap.Int("exitCode", code), zap.Error(err)}
+		if kind := failure.KindOf(err); kind != 0 {
+			fields = append(fields, zap.Stringer("failure", kind))
+		}
+
+		config.EnvSingletons.Logger.Error("The command failed", fields...)
 	}
+
+	config.EnvSingletons.Logger.Sync()
+	os.Exit(code)
 }
 
 func init() {
@@ -57,6 +104,12 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.powerpr.yaml)")
+	rootCmd.PersistentFlags().String("log-level", "info", "Log level: debug, info, warn or error")
+	viper.BindPFlag("LOG_LEVEL", rootCmd.PersistentFlags().Lookup("log-level"))
+	rootCmd.PersistentFlags().String("log-format", logging.FormatJSON, "Log format: json or console")
+	viper.BindPFlag("LOG_FORMAT", rootCmd.PersistentFlags().Lookup("log-format"))
+	viper.BindEnv("LOG_LEVEL", "POWERPR_LOG_LEVEL", "LOG_LEVEL")
+	viper.BindEnv("LOG_FORMAT", "POWERPR_LOG_FORMAT", "LOG_FORMAT")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -81,8 +134,9 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. It is logged once the logger,
+	// which the file configures, is built.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+		cfgFileUsed = viper.ConfigFileUsed()
 	}
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core"
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Receive GitHub webhooks and review the pull requests they are about",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if err = config.LoadSingletons(); err != nil {
+			return
+		}
+		config.EnvConfig.Outputs = serveOutputs
+
+		if config.EnvConfig.CodeHost != codehost.GitHub {
+			return failure.Errorf(failure.Config, "serve only receives GitHub webhooks, CODE_HOST is %s", config.EnvConfig.CodeHost)
+		}
+
+		if err = core.ValidateOutputs(serveOutputs); err != nil {
+			return
+		}
+
+		if err = config.LoadProfiles(); err != nil {
+			return
+		}
+
+		if err = config.LoadPolicy(); err != nil {
+			return
+		}
+
+		s, err := server.New(server.Config{
+			Addr:            viper.GetString("SERVE_ADDR"),
+			Secret:          viper.GetString("WEBHOOK_SECRET"),
+			Workers:         viper.GetInt("SERVE_WORKERS"),
+			QueueSize:       viper.GetInt("SERVE_QUEUE_SIZE"),
+			TriggerLabel:    viper.GetString("TRIGGER_LABEL"),
+			ShutdownTimeout: viper.GetDuration("SHUTDOWN_TIMEOUT"),
+			Logger:          config.EnvSingletons.Logger,
+		}, reviewEvent)
+		if err != nil {
+			return failure.Wrap(failure.Config, err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return s.Run(ctx)
+	},
+}
+
+// reviewEvent reviews the pull request of a webhook event, or runs the
+// command of its comment, with the configuration of the server.
+func reviewEvent(event github.PullRequestEvent) error {
+	cfg := config.EnvConfig
+	cfg.Event = event
+	cfg.GithubRepoOwner = event.Owner
+	cfg.GithubRepoName = event.Repo
+	cfg.GithubPrNumber = event.PrNumber
+
+	return core.Handle(cfg)
+}
+
+var serveOutputs []string
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", ":8080", "Address to listen on")
+	viper.BindPFlag("SERVE_ADDR", serveCmd.Flags().Lookup("addr"))
+	serveCmd.Flags().Int("workers", 4, "Number of reviews run at the same time")
+	viper.BindPFlag("SERVE_WORKERS", serveCmd.Flags().Lookup("workers"))
+	serveCmd.Flags().Int("queue-size", 100, "Number of reviews waiting for a worker before new events are rejected")
+	viper.BindPFlag("SERVE_QUEUE_SIZE", serveCmd.Flags().Lookup("queue-size"))
+	serveCmd.Flags().String("trigger-label", "", "Only review pull requests with this label")
+	viper.BindPFlag("TRIGGER_LABEL", serveCmd.Flags().Lookup("trigger-label"))
+	serveCmd.Flags().Duration("shutdown-timeout", 5*time.Minute, "Time given to running reviews to finish on shutdown")
+	viper.BindPFlag("SHUTDOWN_TIMEOUT", serveCmd.Flags().Lookup("shutdown-timeout"))
+	serveCmd.Flags().StringSliceVar(&serveOutputs, "output", []string{core.OutputReview}, "Where to publish the findings: review or check. Can be repeated")
+
+	// The webhook secret is only read from the environment, never from a flag
+	viper.BindEnv("WEBHOOK_SECRET", "POWERPR_WEBHOOK_SECRET")
+}
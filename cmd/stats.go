@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/stats"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report how the findings posted on the pull requests of a repository were received",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		var (
+			host         string
+			since        time.Time
+			githubConfig github.Config
+			githubClient github.Client
+			findings     []github.FindingComment
+		)
+
+		owner, repo, found := strings.Cut(statsRepo, "/")
+		if !found || owner == "" || repo == "" {
+			return failure.Errorf(failure.Config, "--repo must be owner/repo, got %q", statsRepo)
+		}
+
+		if statsFormat != "table" && statsFormat != "json" {
+			return failure.Errorf(failure.Config, "--format must be table or json, got %q", statsFormat)
+		}
+
+		if host, err = config.CodeHostName(); err != nil {
+			return failure.Wrap(failure.Config, err)
+		}
+
+		if host != codehost.GitHub {
+			return failure.Errorf(failure.Config, "stats is only available on GitHub, CODE_HOST is %s", host)
+		}
+
+		if since, err = stats.ParseSince(statsSince, time.Now()); err != nil {
+			return failure.Errorf(failure.Config, "--since: %w", err)
+		}
+
+		if githubConfig, err = config.GithubConfig(os.Getenv("GITHUB_TOKEN"), ""); err != nil {
+			return failure.Wrap(failure.Config, err)
+		}
+
+		if githubClient, err = github.NewClient(githubConfig); err != nil {
+			return failure.Errorf(failure.Config, "initiating the GitHub client: %w", err)
+		}
+
+		if findings, err = githubClient.GetFindingComments(github.FindingCommentsRequest{
+			Owner: owner,
+			Repo:  repo,
+			Since: since,
+			Bot:   statsBot,
+		}); err != nil {
+			return failure.Errorf(failure.CodeHost, "collecting the findings: %w", err)
+		}
+
+		report := stats.NewReport(statsRepo, since, findings)
+
+		if statsFormat == "json" {
+			return report.WriteJSON(os.Stdout)
+		}
+
+		return report.WriteTable(os.Stdout)
+	},
+}
+
+var (
+	statsRepo   string
+	statsSince  string
+	statsFormat string
+	statsBot    string
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsRepo, "repo", "", "Repository whose pull requests are measured, as owner/repo")
+	statsCmd.MarkFlagRequired("repo")
+	statsCmd.Flags().StringVar(&statsSince, "since", "30d", "Only count the findings posted since then: days (30d), weeks (2w), a duration (12h) or a date (2006-01-02)")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "Output format: table or json")
+	statsCmd.Flags().StringVar(&statsBot, "bot", "", "Login of the bot, to also count the comments posted before findings were marked, such as powerpr[bot]")
+}
@@ -2,60 +2,445 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/failure"
 	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/gitlab"
+	"github.com/lucasmbaia/power-actions/core/httpcache"
+	"github.com/lucasmbaia/power-actions/core/logging"
 	"github.com/lucasmbaia/power-actions/core/openai"
+	"github.com/lucasmbaia/power-actions/core/policy"
+	"github.com/lucasmbaia/power-actions/core/profile"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 var (
-	EnvSingletons Singletons
+	EnvSingletons = Singletons{Logger: zap.NewNop()}
 	EnvConfig     Config
 )
 
 type Singletons struct {
+	// Logger is the logger of every package, built by LoadLogger. It
+	// discards everything until then.
+	Logger       *zap.Logger
 	OpenaiClient openai.Client
-	GithubClient github.Client
+	CodeHost     codehost.Host
 }
 
 type Config struct {
+	// CodeHost is the name of the service hosting the pull request. On
+	// GitLab, the owner and the repository are the namespace and the name of
+	// the project, and the pull request number is the IID of the merge
+	// request.
+	CodeHost string
+
 	GithubRepoOwner string
 	GithubRepoName  string
 	GithubPrNumber  int
 
+	// Event is the GitHub Actions event that triggered the run, if any.
+	Event github.PullRequestEvent
+
 	MaxChangedLines int
+	FetchWorkers    int
 	OpenaiModel     string
+	Temperature     float32
+
+	MaxPromptTokens    int
+	ConversationTokens int
+	ContextLines       int
+	SmallFileLines     int
+
+	Walkthrough bool
+
+	// Verify sends each finding back to VerifyModel, or to OpenaiModel when
+	// it is empty, to be confirmed, downgraded or rejected.
+	Verify      bool
+	VerifyModel string
+
+	// DryRun prints the findings and their verdicts instead of publishing
+	// them.
+	DryRun bool
+
+	// Files, when set, restricts the review to these files and to the files
+	// under these directories.
+	Files []string
+
+	// Profiles are the review profiles a pull request can select with its
+	// labels.
+	Profiles []profile.Profile
+
+	// Policy is the data-governance policy: what is never sent to the model,
+	// and the providers each repository may be sent to.
+	Policy policy.Policy
+
+	Outputs              []string
+	CheckFailureSeverity github.Severity
+	CheckNeutralSeverity github.Severity
+
+	// FailOn fails the review when a posted finding is at least this severe,
+	// unless it is empty.
+	FailOn github.Severity
 }
 
-func LoadSingletons() {
-	var err error
+// LoadSingletons builds the clients and reads the settings of the
+// environment into EnvConfig. Its errors are configuration failures.
+func LoadSingletons() error {
+	return failure.Wrap(failure.Config, loadSingletons())
+}
+
+func loadSingletons() (err error) {
+	var openaiCache *openai.Cache
+
+	if dir := os.Getenv("LLM_CACHE_DIR"); dir != "" {
+		var ttl time.Duration
+
+		if ttl, err = getDurationEnv("LLM_CACHE_TTL", 24*time.Hour); err != nil {
+			return
+		}
+
+		if openaiCache, err = openai.NewCache(dir, ttl); err != nil {
+			return fmt.Errorf("creating the chat completion cache: %w", err)
+		}
+	}
 
 	if EnvSingletons.OpenaiClient, err = openai.NewClient(openai.Config{
-		Key: os.Getenv("POWERPR_OPENAI_KEY"),
+		Key:    os.Getenv("POWERPR_OPENAI_KEY"),
+		Cache:  openaiCache,
+		Logger: EnvSingletons.Logger,
 	}); err != nil {
-		log.Fatalf("Error to initiate openai client: %s", err.Error())
+		return fmt.Errorf("creating the openai client: %w", err)
 	}
 
-	EnvSingletons.GithubClient = github.NewClient(os.Getenv("GITHUB_TOKEN"))
+	if EnvConfig.CodeHost, err = CodeHostName(); err != nil {
+		return
+	}
+
+	switch EnvConfig.CodeHost {
+	case codehost.GitHub:
+		var (
+			githubConfig github.Config
+			githubClient github.Client
+		)
+
+		if githubConfig, err = GithubConfig(os.Getenv("GITHUB_TOKEN"), ""); err != nil {
+			return
+		}
+
+		if githubClient, err = github.NewClient(githubConfig); err != nil {
+			return fmt.Errorf("creating the github client: %w", err)
+		}
+		EnvSingletons.CodeHost = &githubClient
+	case codehost.GitLab:
+		var gitlabClient gitlab.Client
+
+		if gitlabClient, err = gitlab.NewClient(GitlabConfig(os.Getenv("GITLAB_TOKEN"), "")); err != nil {
+			return fmt.Errorf("creating the gitlab client: %w", err)
+		}
+		EnvSingletons.CodeHost = &gitlabClient
+	}
 
-	EnvConfig.GithubRepoOwner = os.Getenv("GITHUB_OWNER")
-	EnvConfig.GithubRepoName = strings.Replace(os.Getenv("GITHUB_REPO"), fmt.Sprintf("%s/", EnvConfig.GithubRepoOwner), "", -1)
 	EnvConfig.OpenaiModel = os.Getenv("OPENAI_MODEL")
-	EnvConfig.MaxChangedLines = 500
+	EnvConfig.Temperature = 0.5
+
+	if EnvConfig.MaxChangedLines, err = getUnsignedIntEnv("MAX_CHANGED_LINES", 500); err != nil {
+		return
+	}
+	if EnvConfig.MaxChangedLines <= 0 {
+		return fmt.Errorf("MAX_CHANGED_LINES need to be a positive integer")
+	}
+
+	if EnvConfig.FetchWorkers, err = getUnsignedIntEnv("FETCH_WORKERS", github.DefaultFetchWorkers); err != nil {
+		return
+	}
+	if EnvConfig.FetchWorkers <= 0 {
+		return fmt.Errorf("FETCH_WORKERS need to be a positive integer")
+	}
+
+	if EnvConfig.MaxPromptTokens, err = getUnsignedIntEnv("MAX_PROMPT_TOKENS", 60000); err != nil {
+		return
+	}
+	if EnvConfig.MaxPromptTokens <= 0 {
+		return fmt.Errorf("MAX_PROMPT_TOKENS need to be a positive integer")
+	}
+
+	if EnvConfig.ConversationTokens, err = getUnsignedIntEnv("CONVERSATION_TOKENS", 8000); err != nil {
+		return
+	}
+	if EnvConfig.ConversationTokens < 0 {
+		return fmt.Errorf("CONVERSATION_TOKENS can not be negative")
+	}
+
+	if EnvConfig.ContextLines, err = getUnsignedIntEnv("CONTEXT_LINES", 20); err != nil {
+		return
+	}
+	if EnvConfig.ContextLines < 0 {
+		return fmt.Errorf("CONTEXT_LINES can not be negative")
+	}
+
+	if EnvConfig.SmallFileLines, err = getUnsignedIntEnv("SMALL_FILE_LINES", 150); err != nil {
+		return
+	}
+	if EnvConfig.SmallFileLines < 0 {
+		return fmt.Errorf("SMALL_FILE_LINES can not be negative")
+	}
+
+	if EnvConfig.Walkthrough, err = getBoolEnv("WALKTHROUGH", false); err != nil {
+		return
+	}
+
+	if EnvConfig.Verify, err = getBoolEnv("VERIFY", false); err != nil {
+		return
+	}
+	EnvConfig.VerifyModel = os.Getenv("VERIFY_MODEL")
+
+	if EnvConfig.CheckFailureSeverity, err = getSeverityEnv("CHECK_FAILURE_SEVERITY", github.SeverityHigh); err != nil {
+		return
+	}
+
+	if EnvConfig.CheckNeutralSeverity, err = getSeverityEnv("CHECK_NEUTRAL_SEVERITY", github.SeverityMedium); err != nil {
+		return
+	}
+
+	return
+}
+
+// LoadLogger builds EnvSingletons.Logger, which writes to stderr at the
+// LOG_LEVEL level in the LOG_FORMAT format. The values of the environment
+// variables holding credentials, and the patterns of the log.redact key of
+// the configuration file, are masked in its lines.
+func LoadLogger() (err error) {
+	var logger *zap.Logger
+
+	if logger, err = logging.New(logging.Config{
+		Level:    viper.GetString("LOG_LEVEL"),
+		Format:   viper.GetString("LOG_FORMAT"),
+		Secrets:  credentials(os.Environ()),
+		Patterns: viper.GetStringSlice("log.redact"),
+	}, os.Stderr); err != nil {
+		return
+	}
+
+	EnvSingletons.Logger = logger
+
+	return
+}
+
+// credentials returns the values of the environment variables whose name
+// tells they hold a credential, such as GITHUB_TOKEN or POWERPR_OPENAI_KEY.
+func credentials(environ []string) (values []string) {
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+
+		for _, suffix := range []string{"TOKEN", "KEY", "SECRET", "PASSWORD"} {
+			if strings.HasSuffix(strings.ToUpper(name), suffix) {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// GithubConfig returns the configuration of the GitHub clients. The API of a
+// GitHub Enterprise Server is read from GITHUB_API_URL, which GitHub Actions
+// sets, or defaults to defaultBaseURL, and its upload URL from
+// GITHUB_UPLOAD_URL. A GitHub App is
+// used when GITHUB_APP_ID is set, with its private key in
+// GITHUB_APP_PRIVATE_KEY or in the file at GITHUB_APP_PRIVATE_KEY_PATH, and
+// optionally its installation in GITHUB_APP_INSTALLATION_ID. Otherwise token
+// is used. Responses are cached on disk in GITHUB_CACHE_DIR, or in memory when
+// it is not set.
+func GithubConfig(token, defaultBaseURL string) (cfg github.Config, err error) {
+	var appCfg github.AppConfig
+
+	cfg.Token = token
+	cfg.BaseURL = os.Getenv("GITHUB_API_URL")
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	cfg.UploadURL = os.Getenv("GITHUB_UPLOAD_URL")
+
+	if dir := os.Getenv("GITHUB_CACHE_DIR"); dir != "" {
+		if cfg.Cache, err = httpcache.NewDiskStore(dir); err != nil {
+			return cfg, fmt.Errorf("creating the cache directory: %w", err)
+		}
+	} else {
+		cfg.Cache = httpcache.NewMemoryStore(0)
+	}
+
+	if os.Getenv("GITHUB_APP_ID") == "" {
+		return
+	}
+
+	if appCfg.ID, err = strconv.ParseInt(os.Getenv("GITHUB_APP_ID"), 10, 64); err != nil {
+		return cfg, fmt.Errorf("environment variable GITHUB_APP_ID is not a valid integer: %v", err)
+	}
+
+	if installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID"); installationID != "" {
+		if appCfg.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+			return cfg, fmt.Errorf("environment variable GITHUB_APP_INSTALLATION_ID is not a valid integer: %v", err)
+		}
+	}
+
+	appCfg.PrivateKey = []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	if path := os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"); path != "" {
+		if appCfg.PrivateKey, err = os.ReadFile(path); err != nil {
+			return cfg, fmt.Errorf("reading the app private key: %w", err)
+		}
+	}
+
+	if github.IsEnterprise(cfg.BaseURL) {
+		appCfg.BaseURL = github.APIBaseURL(cfg.BaseURL)
+	}
+
+	cfg.App, err = github.NewApp(appCfg)
+
+	return
+}
+
+// LoadProfiles loads the review profiles: the built-in ones, which the
+// profiles key of the configuration file overrides and completes.
+func LoadProfiles() (err error) {
+	var (
+		profiles   = profile.Defaults()
+		configured map[string]profile.Profile
+	)
+
+	if err = viper.UnmarshalKey("profiles", &configured); err != nil {
+		return failure.Errorf(failure.Config, "reading the profiles: %w", err)
+	}
+
+	for name, p := range configured {
+		profiles[name] = p
+	}
+
+	EnvConfig.Profiles, err = profile.Load(profiles)
+
+	return failure.Wrap(failure.Config, err)
+}
+
+// LoadPolicy loads the data-governance policy of the policy key of the
+// configuration file. Everything may be sent when there is none.
+func LoadPolicy() (err error) {
+	var configured policy.Policy
+
+	if err = viper.UnmarshalKey("policy", &configured); err != nil {
+		return failure.Errorf(failure.Config, "reading the policy: %w", err)
+	}
+
+	EnvConfig.Policy, err = policy.Load(configured)
+
+	return failure.Wrap(failure.Config, err)
+}
+
+// CodeHostName returns the code host set in CODE_HOST, which defaults to
+// GitLab in GitLab CI and to GitHub everywhere else.
+func CodeHostName() (string, error) {
+	if name := os.Getenv("CODE_HOST"); name != "" {
+		return codehost.ParseName(name)
+	}
+
+	if os.Getenv("GITLAB_CI") == "true" {
+		return codehost.GitLab, nil
+	}
 
-	if EnvConfig.MaxChangedLines, err = getUnsignedIntEnv("MAX_CHANGED_LINES", 500); EnvConfig.MaxChangedLines <= 0 || err != nil {
-		if EnvConfig.MaxChangedLines <= 0 {
-			log.Fatalf("MAX_CHANGED_LINES need to be a positive integer")
+	return codehost.GitHub, nil
+}
+
+// GitlabConfig returns the configuration of the GitLab client. The API is
+// read from CI_API_V4_URL, which GitLab CI sets, then from GITLAB_API_URL,
+// and defaults to defaultBaseURL.
+func GitlabConfig(token, defaultBaseURL string) (cfg gitlab.Config) {
+	cfg.Token = token
+	cfg.BaseURL = defaultBaseURL
+
+	for _, name := range []string{"GITLAB_API_URL", "CI_API_V4_URL"} {
+		if baseURL := os.Getenv(name); baseURL != "" {
+			cfg.BaseURL = baseURL
 		}
-		log.Fatal(err)
 	}
 
-	if EnvConfig.GithubPrNumber, err = strconv.Atoi(os.Getenv("GITHUB_PR_NUMBER")); err != nil {
-		log.Fatal(err)
+	return
+}
+
+// LoadPullRequest sets the coordinates of the pull request to review from the
+// GitHub Actions event payload at GITHUB_EVENT_PATH, when there is one. The
+// GITHUB_OWNER, GITHUB_REPO ("repo" or "owner/repo") and GITHUB_PR_NUMBER
+// variables override the values of the event. On GitLab, the merge request is
+// read from the CI_PROJECT_PATH and CI_MERGE_REQUEST_IID variables of merge
+// request pipelines instead of an event.
+//
+// Its errors are configuration failures.
+func LoadPullRequest() error {
+	return failure.Wrap(failure.Config, loadPullRequest())
+}
+
+func loadPullRequest() (err error) {
+	var eventErr error
+
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		var payload []byte
+		if payload, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("reading the event payload: %w", err)
+		}
+
+		EnvConfig.Event, eventErr = github.ParseEvent(os.Getenv("GITHUB_EVENT_NAME"), payload)
+	}
+
+	EnvConfig.GithubRepoOwner = EnvConfig.Event.Owner
+	EnvConfig.GithubRepoName = EnvConfig.Event.Repo
+	EnvConfig.GithubPrNumber = EnvConfig.Event.PrNumber
+
+	if EnvConfig.CodeHost == codehost.GitLab {
+		if path := os.Getenv("CI_PROJECT_PATH"); strings.Contains(path, "/") {
+			i := strings.LastIndex(path, "/")
+			EnvConfig.GithubRepoOwner, EnvConfig.GithubRepoName = path[:i], path[i+1:]
+		}
+
+		if iid := os.Getenv("CI_MERGE_REQUEST_IID"); iid != "" {
+			if EnvConfig.GithubPrNumber, err = strconv.Atoi(iid); err != nil {
+				return fmt.Errorf("environment variable CI_MERGE_REQUEST_IID is not a valid integer: %v", err)
+			}
+		}
+	}
+
+	if owner := os.Getenv("GITHUB_OWNER"); owner != "" {
+		EnvConfig.GithubRepoOwner = owner
+	}
+
+	if repo := os.Getenv("GITHUB_REPO"); repo != "" {
+		if owner, name, found := strings.Cut(repo, "/"); found {
+			if os.Getenv("GITHUB_OWNER") == "" {
+				EnvConfig.GithubRepoOwner = owner
+			}
+			repo = name
+		}
+		EnvConfig.GithubRepoName = repo
 	}
+
+	if number := os.Getenv("GITHUB_PR_NUMBER"); number != "" {
+		if EnvConfig.GithubPrNumber, err = strconv.Atoi(number); err != nil {
+			return fmt.Errorf("environment variable GITHUB_PR_NUMBER is not a valid integer: %v", err)
+		}
+	}
+
+	if EnvConfig.GithubRepoOwner == "" || EnvConfig.GithubRepoName == "" || EnvConfig.GithubPrNumber <= 0 {
+		if eventErr != nil {
+			return fmt.Errorf("the pull request to review is unknown: %v", eventErr)
+		}
+
+		return fmt.Errorf("the pull request to review is unknown: run from a pull request event, a merge request pipeline or set GITHUB_OWNER, GITHUB_REPO and GITHUB_PR_NUMBER")
+	}
+
+	return
 }
 
 func getUnsignedIntEnv(varName string, defaultValue int) (int, error) {
@@ -73,3 +458,51 @@ func getUnsignedIntEnv(varName string, defaultValue int) (int, error) {
 
 	return value, nil
 }
+
+func getDurationEnv(varName string, defaultValue time.Duration) (time.Duration, error) {
+	valueStr := os.Getenv(varName)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("environment variable %s is not a valid duration: %q", varName, valueStr)
+	}
+
+	return value, nil
+}
+
+func getBoolEnv(varName string, defaultValue bool) (bool, error) {
+	valueStr := os.Getenv(varName)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, fmt.Errorf("environment variable %s is not a valid boolean: %v", varName, err)
+	}
+
+	return value, nil
+}
+
+// getSeverityEnv reads a severity threshold. The value "none" disables the
+// threshold, which is returned as an empty severity.
+func getSeverityEnv(varName string, defaultValue github.Severity) (github.Severity, error) {
+	valueStr := os.Getenv(varName)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	if strings.EqualFold(valueStr, "none") {
+		return "", nil
+	}
+
+	value, err := github.ParseSeverity(valueStr)
+	if err != nil {
+		return "", fmt.Errorf("environment variable %s is not valid: %v", varName, err)
+	}
+
+	return value, nil
+}
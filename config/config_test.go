@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/profile"
+	"github.com/spf13/viper"
+)
+
+func Test_LoadPullRequest(t *testing.T) {
+	const payload = `{"action": "labeled", "label": {"name": "ai-reviewer"}, "pull_request": {"number": 15, "head": {"sha": "h"}, "base": {"sha": "b"}}, "repository": {"name": "repo", "owner": {"login": "owner"}}, "sender": {"login": "octocat"}}`
+
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(payload), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name          string
+		env           map[string]string
+		owner         string
+		repo          string
+		number        int
+		codeHost      string
+		errorExpected bool
+	}{
+		{
+			"from the event",
+			map[string]string{"GITHUB_EVENT_PATH": eventPath, "GITHUB_EVENT_NAME": "pull_request_target"},
+			"owner", "repo", 15, "", false,
+		},
+		{
+			"environment overrides the event",
+			map[string]string{"GITHUB_EVENT_PATH": eventPath, "GITHUB_EVENT_NAME": "pull_request", "GITHUB_REPO": "other/project", "GITHUB_PR_NUMBER": "3"},
+			"other", "project", 3, "", false,
+		},
+		{
+			"environment only",
+			map[string]string{"GITHUB_OWNER": "owner", "GITHUB_REPO": "owner/repo", "GITHUB_PR_NUMBER": "4"},
+			"owner", "repo", 4, "", false,
+		},
+		{
+			"unsupported event without overrides",
+			map[string]string{"GITHUB_EVENT_PATH": eventPath, "GITHUB_EVENT_NAME": "workflow_dispatch"},
+			"", "", 0, "", true,
+		},
+		{
+			"unsupported event with overrides",
+			map[string]string{"GITHUB_EVENT_PATH": eventPath, "GITHUB_EVENT_NAME": "workflow_dispatch", "GITHUB_REPO": "owner/repo", "GITHUB_PR_NUMBER": "5"},
+			"owner", "repo", 5, "", false,
+		},
+		{
+			"invalid number",
+			map[string]string{"GITHUB_OWNER": "owner", "GITHUB_REPO": "repo", "GITHUB_PR_NUMBER": "abc"},
+			"", "", 0, "", true,
+		},
+		{
+			"gitlab merge request pipeline",
+			map[string]string{"CI_PROJECT_PATH": "group/sub/project", "CI_MERGE_REQUEST_IID": "8"},
+			"group/sub", "project", 8, codehost.GitLab, false,
+		},
+		{
+			"gitlab variables are ignored on github",
+			map[string]string{"CI_PROJECT_PATH": "group/project", "CI_MERGE_REQUEST_IID": "8"},
+			"", "", 0, codehost.GitHub, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"GITHUB_EVENT_PATH", "GITHUB_EVENT_NAME", "GITHUB_OWNER", "GITHUB_REPO", "GITHUB_PR_NUMBER", "CI_PROJECT_PATH", "CI_MERGE_REQUEST_IID"} {
+				t.Setenv(name, tt.env[name])
+			}
+			EnvConfig = Config{CodeHost: tt.codeHost}
+
+			err := LoadPullRequest()
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && (EnvConfig.GithubRepoOwner != tt.owner || EnvConfig.GithubRepoName != tt.repo || EnvConfig.GithubPrNumber != tt.number) {
+				t.Fatalf("expected %s/%s#%d, got %s/%s#%d", tt.owner, tt.repo, tt.number, EnvConfig.GithubRepoOwner, EnvConfig.GithubRepoName, EnvConfig.GithubPrNumber)
+			}
+		})
+	}
+}
+
+func Test_LoadProfiles(t *testing.T) {
+	const file = `
+profiles:
+  quick:
+    model: gpt-4o-mini
+    severity: critical
+  docs:
+    labels: [documentation]
+    prompt: deep
+    temperature: 0
+    include: ["**/*.md"]
+`
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+
+	EnvConfig = Config{}
+	if err := LoadProfiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := make(map[string]profile.Profile)
+	for _, p := range EnvConfig.Profiles {
+		profiles[p.Name] = p
+	}
+
+	if len(profiles) != 4 || profiles["security"].Prompt != "security" {
+		t.Fatalf("expected the built-in profiles and docs, got %+v", EnvConfig.Profiles)
+	}
+
+	if quick := profiles["quick"]; quick.Model != "gpt-4o-mini" || quick.Severity != "critical" || quick.Prompt != "" {
+		t.Fatalf("expected quick to be overridden, got %+v", quick)
+	}
+
+	if docs := profiles["docs"]; docs.Labels[0] != "documentation" || docs.Temperature == nil || *docs.Temperature != 0 || docs.Include[0] != "**/*.md" {
+		t.Fatalf("unexpected docs profile %+v", docs)
+	}
+}
+
+func Test_LoadPolicy(t *testing.T) {
+	const file = `
+policy:
+  paths: ["fixtures/customers/**"]
+  patterns: ["(?i)licensed to acme corp"]
+  providers: [openai]
+  repositories:
+    - name: acme/payments.js
+      providers: []
+`
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+
+	EnvConfig = Config{}
+	if err := LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, withheld := EnvConfig.Policy.Withholds("fixtures/customers/orders.csv"); !withheld {
+		t.Fatal("expected the customer fixtures to be withheld")
+	}
+
+	if err := EnvConfig.Policy.Allow("acme/payments.js", "openai"); err == nil {
+		t.Fatal("expected acme/payments.js not to be sent to openai")
+	}
+
+	if err := EnvConfig.Policy.Allow("acme/web", "openai"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_credentials(t *testing.T) {
+	environ := []string{"GITHUB_TOKEN=ghs_token", "POWERPR_OPENAI_KEY=sk-key", "POWERPR_WEBHOOK_SECRET=secret", "GITHUB_APP_PRIVATE_KEY_PATH=/key.pem", "HOME=/root"}
+
+	if values := credentials(environ); strings.Join(values, ",") != "ghs_token,sk-key,secret" {
+		t.Fatalf("unexpected credentials %v", values)
+	}
+}
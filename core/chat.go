@@ -0,0 +1,48 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/openai"
+)
+
+// complete sends the system and user prompts to the model and decodes its
+// JSON answer, optionally wrapped in a markdown code fence, into v. Nothing is
+// sent when the policy does not allow the provider for the repository.
+func complete(cfg config.Config, system, user string, v interface{}) (err error) {
+	var chatResponse openai.ChatCompletionResponse
+
+	if err = cfg.Policy.Allow(cfg.GithubRepoOwner+"/"+cfg.GithubRepoName, openai.Provider); err != nil {
+		return failure.Wrap(failure.Config, err)
+	}
+
+	if chatResponse, err = config.EnvSingletons.OpenaiClient.CreateChatCompletion(openai.ChatCompletionRequest{
+		Model: cfg.OpenaiModel,
+		Messages: []openai.ChatMessages{{
+			Role:    "system",
+			Content: system,
+		}, {
+			Role:    "user",
+			Content: user,
+		}},
+		Temperature: cfg.Temperature,
+	}); err != nil {
+		return failure.Wrap(failure.LLM, err)
+	}
+
+	if len(chatResponse.Choices) == 0 {
+		return failure.Errorf(failure.LLM, "the model returned no choices")
+	}
+
+	answer := strings.Replace(chatResponse.Choices[0].Message.Content, "```json", "", 1)
+	answer = strings.Replace(answer, "```", "", 1)
+
+	if err = json.Unmarshal([]byte(answer), v); err != nil {
+		return failure.Errorf(failure.Parse, "parsing the answer of the model: %w", err)
+	}
+
+	return
+}
@@ -0,0 +1,72 @@
+// Package codehost describes the services hosting the pull requests that are
+// reviewed, so the review does not depend on any of them.
+package codehost
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+// Supported code hosts.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Host fetches the changes of a pull request, or of a merge request on
+// GitLab, and publishes its review. The owner and the repository of a
+// request are the namespace and the name of the project on GitLab.
+type Host interface {
+	// GetPullRequestChanges returns the pull request with its commits and the
+	// review comments made on their files.
+	GetPullRequestChanges(prr github.PullRequestReviewRequest) (content.PullRequest, error)
+	// GetLinkedIssues returns the issues the pull request refers to.
+	GetLinkedIssues(prr github.PullRequestReviewRequest, pr content.PullRequest) ([]content.Issue, error)
+	// GetConversation returns the discussion of the pull request that is not
+	// attached to a line, in chronological order.
+	GetConversation(prr github.PullRequestReviewRequest) ([]content.Post, error)
+	// GetFileContent returns the content of a file at the given ref.
+	GetFileContent(owner, repo, path, ref string) (string, error)
+	// PullRequestReview posts prr.Comment and one comment per finding on the
+	// lines of the new version of the files.
+	PullRequestReview(prr github.PullRequestReviewRequest) error
+	// Login returns the login of the account the host is accessed with.
+	Login() (string, error)
+	// UpsertIssueComment posts a comment on the pull request, or edits the
+	// one of Login starting with marker.
+	UpsertIssueComment(prr github.PullRequestReviewRequest, marker, body string) error
+	// CreatePullRequest opens a pull request and returns its URL.
+	CreatePullRequest(npr github.NewPullRequest) (url string, err error)
+}
+
+// CheckRunner is implemented by the hosts that can publish the findings as a
+// check run.
+type CheckRunner interface {
+	CreateCheckRun(prr github.PullRequestReviewRequest, cr github.CheckRunRequest) error
+}
+
+// Commander is implemented by the hosts whose comments can hold commands, as
+// long as their authors can write to the repository.
+type Commander interface {
+	HasWriteAccess(owner, repo, user string) (bool, error)
+	ReactToComment(event github.PullRequestEvent, reaction string) error
+}
+
+var (
+	_ Host        = (*github.Client)(nil)
+	_ CheckRunner = (*github.Client)(nil)
+	_ Commander   = (*github.Client)(nil)
+)
+
+// ParseName validates the name of a code host.
+func ParseName(name string) (string, error) {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case GitHub, GitLab:
+		return name, nil
+	}
+
+	return "", fmt.Errorf("unknown code host %q, expected %q or %q", name, GitHub, GitLab)
+}
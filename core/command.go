@@ -0,0 +1,287 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/command"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/prompt"
+	"github.com/lucasmbaia/power-actions/core/snippet"
+	"github.com/lucasmbaia/power-actions/core/verify"
+	"go.uber.org/zap"
+)
+
+// Handle runs the command of the comment that triggered cfg.Event, and
+// reviews the pull request for any other event. Comments that are edited or
+// deleted, that hold no command or that the bot wrote itself are skipped, so
+// the comments of a review never trigger another one. Commands are only run
+// for the users who can write to the repository, and their comment gets a
+// reaction telling whether the command is run.
+func Handle(cfg config.Config) (err error) {
+	var (
+		cmd      command.Command
+		found    bool
+		parseErr error
+		allowed  bool
+		login    string
+	)
+
+	if !isComment(cfg.Event) {
+		return Review(cfg)
+	}
+
+	if cfg.Event.Action != "created" {
+		config.EnvSingletons.Logger.Info("Skipped the comment event", zap.String("action", cfg.Event.Action))
+		return nil
+	}
+
+	if cmd, found, parseErr = command.Parse(cfg.Event.CommentBody); !found {
+		config.EnvSingletons.Logger.Info("Skipped the comment, it is not a command", zap.Int64("comment", cfg.Event.CommentID))
+		return nil
+	}
+
+	if login, err = config.EnvSingletons.CodeHost.Login(); err != nil {
+		config.EnvSingletons.Logger.Warn("Error to get the login of the bot", zap.Error(err))
+		err = nil
+	} else if strings.EqualFold(cfg.Event.Actor, login) {
+		config.EnvSingletons.Logger.Info("Skipped the comment of the bot", zap.Int64("comment", cfg.Event.CommentID))
+		return nil
+	}
+
+	commander, ok := config.EnvSingletons.CodeHost.(codehost.Commander)
+	if !ok {
+		return failure.Errorf(failure.Config, "commands are not supported on %s", cfg.CodeHost)
+	}
+
+	if allowed, err = commander.HasWriteAccess(cfg.GithubRepoOwner, cfg.GithubRepoName, cfg.Event.Actor); err != nil {
+		return failure.Errorf(failure.CodeHost, "checking the permission of %s: %w", cfg.Event.Actor, err)
+	}
+
+	if !allowed {
+		react(cfg, commander, github.ReactionDenied)
+		return fmt.Errorf("%s needs write access to %s/%s to run commands", cfg.Event.Actor, cfg.GithubRepoOwner, cfg.GithubRepoName)
+	}
+
+	if parseErr != nil {
+		react(cfg, commander, github.ReactionConfused)
+		return failure.Wrap(failure.Parse, parseErr)
+	}
+
+	react(cfg, commander, github.ReactionAcknowledged)
+
+	switch cmd.Name {
+	case command.Summarize:
+		return summarize(cfg)
+	case command.Explain:
+		return explain(cfg, cmd.Args[0])
+	case command.Ignore:
+		return ignore(cfg, cmd.Args[0])
+	}
+
+	cfg.Files = cmd.Args
+
+	return Review(cfg)
+}
+
+// isComment reports whether event is about a comment of the pull request.
+func isComment(event github.PullRequestEvent) bool {
+	return event.CommentID != 0 || event.Name == github.EventIssueComment || event.Name == github.EventPullRequestReviewComment
+}
+
+// react adds a reaction to the comment of the command, unless it is a dry
+// run. Failing to react does not keep the command from running.
+func react(cfg config.Config, commander codehost.Commander, reaction string) {
+	if cfg.DryRun {
+		return
+	}
+
+	if err := commander.ReactToComment(cfg.Event, reaction); err != nil {
+		config.EnvSingletons.Logger.Warn("Error to react to the comment", zap.Int64("comment", cfg.Event.CommentID), zap.Error(err))
+	}
+}
+
+// summarize posts the walkthrough of the pull request.
+func summarize(cfg config.Config) (err error) {
+	var p prepared
+
+	if p, err = prepare(&cfg); err != nil {
+		return
+	}
+
+	rendered, _ := payload(cfg, &p.pullRequest)
+
+	return postWalkthrough(cfg, p.prr, rendered)
+}
+
+type explanation struct {
+	Explanation string `json:"explanation"`
+}
+
+// explain answers the comment of the command with the explanation of the
+// lines of arg, a link to lines of a file or "file:line". Lines the pull
+// request does not change are explained from the file at its head.
+func explain(cfg config.Config, arg string) (err error) {
+	var (
+		loc    command.Location
+		p      prepared
+		answer explanation
+	)
+
+	if loc, err = command.ParseLocation(arg); err != nil {
+		return failure.Wrap(failure.Parse, err)
+	}
+
+	if loc.File != "" {
+		cfg.Files = []string{loc.File}
+	}
+
+	if p, err = prepare(&cfg); err != nil {
+		return
+	}
+
+	pullRequest := p.pullRequest
+
+	if loc.DiffHash != "" {
+		if loc.File, err = diffFile(pullRequest, loc.DiffHash); err != nil {
+			return failure.Wrap(failure.Parse, err)
+		}
+	}
+
+	lines := github.Review{File: loc.File, StartLine: loc.StartLine, LineNumber: loc.EndLine}
+
+	excerpt := verify.Excerpt(pullRequest, lines)
+	if len(excerpt.Commits) == 0 {
+		if excerpt.Commits, err = unchangedLines(cfg, pullRequest.HeadSHA, lines); err != nil {
+			return
+		}
+	}
+
+	rendered, _ := payload(cfg, &excerpt)
+	if !hasFiles(excerpt) {
+		return failure.Errorf(failure.Config, "%s is withheld from the model by the data-governance policy", loc.File)
+	}
+
+	message := fmt.Sprintf("Explain the lines %d to %d of %s.\n\n%s", loc.StartLine, loc.EndLine, loc.File, rendered)
+	if err = complete(cfg, prompt.EXPLAIN_PROMPT, message, &answer); err != nil {
+		return
+	}
+
+	marker := fmt.Sprintf("<!-- powerpr:explain:%d -->", cfg.Event.CommentID)
+	body := fmt.Sprintf("%s\n> %s %s %s\n\n%s\n", marker, command.Prefix, command.Explain, arg, strings.TrimSpace(answer.Explanation))
+
+	return postComment(cfg, p.prr, marker, body)
+}
+
+// hasFiles reports whether a commit of pr has files left.
+func hasFiles(pr content.PullRequest) bool {
+	for _, commit := range pr.Commits {
+		if len(commit.Files) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diffFile returns the file of the pull request whose name has hash as
+// SHA-256, the identifier of the files in the links to its diff.
+func diffFile(pr content.PullRequest, hash string) (string, error) {
+	for _, commit := range pr.Commits {
+		for _, file := range commit.Files {
+			sum := sha256.Sum256([]byte(file.Filename))
+			if hex.EncodeToString(sum[:]) == hash {
+				return file.Filename, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("the link is not to a file of the pull request")
+}
+
+// unchangedLines returns the lines of a file at the head of the pull request,
+// with the source surrounding them, as a commit of the head.
+func unchangedLines(cfg config.Config, head string, lines github.Review) (commits []content.Commit, err error) {
+	var source string
+
+	if source, err = config.EnvSingletons.CodeHost.GetFileContent(cfg.GithubRepoOwner, cfg.GithubRepoName, lines.File, head); err != nil {
+		err = failure.Wrap(failure.CodeHost, err)
+		return
+	}
+
+	start, end := lines.Lines()
+	hunks := []content.Hunk{{NewStart: start, NewLines: end - start + 1}}
+
+	return []content.Commit{{
+		SHA:   head,
+		Files: []content.File{{Filename: lines.File, Context: snippet.Context(lines.File, source, hunks, cfg.ContextLines, cfg.SmallFileLines)}},
+	}}, nil
+}
+
+// ignore adds the ID of a finding to the comment listing the findings left
+// out of the reviews of the pull request.
+func ignore(cfg config.Config, id string) (err error) {
+	var (
+		posts   []content.Post
+		login   string
+		ignored []string
+	)
+
+	prr := github.PullRequestReviewRequest{Owner: cfg.GithubRepoOwner, Repo: cfg.GithubRepoName, PrNumber: cfg.GithubPrNumber}
+
+	if posts, err = config.EnvSingletons.CodeHost.GetConversation(prr); err != nil {
+		return failure.Wrap(failure.CodeHost, err)
+	}
+
+	if login, err = config.EnvSingletons.CodeHost.Login(); err != nil {
+		return failure.Wrap(failure.CodeHost, err)
+	}
+
+	ignored = command.IgnoredBy(posts, login)
+
+	for _, ignoredID := range ignored {
+		if ignoredID == id {
+			return
+		}
+	}
+
+	return postComment(cfg, prr, command.IgnoredMarker, command.IgnoredComment(append(ignored, id)))
+}
+
+// dropIgnored drops the findings whose ID is in ignored.
+func dropIgnored(reviews github.Reviews, ignored []string) (kept github.Reviews) {
+	if len(ignored) == 0 {
+		return reviews
+	}
+
+	skip := make(map[string]bool)
+	for _, id := range ignored {
+		skip[id] = true
+	}
+
+	for _, review := range reviews.Review {
+		if !skip[review.ID()] {
+			kept.Review = append(kept.Review, review)
+		}
+	}
+
+	return
+}
+
+// postComment posts body as a comment of the pull request, editing the one
+// starting with marker, or prints it on a dry run.
+func postComment(cfg config.Config, prr github.PullRequestReviewRequest, marker, body string) (err error) {
+	if cfg.DryRun {
+		_, err = fmt.Fprintln(os.Stdout, body)
+		return
+	}
+
+	return failure.Wrap(failure.CodeHost, config.EnvSingletons.CodeHost.UpsertIssueComment(prr, marker, body))
+}
@@ -0,0 +1,166 @@
+// Package command parses the slash commands that drive the bot from the
+// comments of a pull request, such as "/powerpr review" or
+// "/powerpr ignore 1a2b3c4d".
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// Prefix starts the comments that hold a command.
+const Prefix = "/powerpr"
+
+// Names of the commands.
+const (
+	// Review reviews the pull request, or only the files given as
+	// arguments.
+	Review = "review"
+	// Summarize posts the walkthrough of the pull request.
+	Summarize = "summarize"
+	// Explain explains the lines of a link, or of "file:line".
+	Explain = "explain"
+	// Ignore hides a finding, given its ID, from the later reviews.
+	Ignore = "ignore"
+)
+
+// Command is a command of a comment.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// Parse returns the command of a comment, if the comment starts with Prefix.
+// A bare "/powerpr" is a review. Commands that are unknown or miss their
+// arguments are reported as errors.
+func Parse(body string) (cmd Command, found bool, err error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != Prefix {
+		return
+	}
+	found = true
+
+	if len(fields) == 1 {
+		return Command{Name: Review}, true, nil
+	}
+
+	cmd = Command{Name: strings.ToLower(fields[1]), Args: fields[2:]}
+
+	switch cmd.Name {
+	case Review, Summarize:
+	case Explain, Ignore:
+		if len(cmd.Args) != 1 {
+			err = fmt.Errorf("%s %s expects a single argument", Prefix, cmd.Name)
+		} else if cmd.Name == Ignore && !findingID.MatchString(cmd.Args[0]) {
+			err = fmt.Errorf("%q is not the ID of a finding", cmd.Args[0])
+		}
+	default:
+		err = fmt.Errorf("unknown command %s %s, expected %s, %s, %s or %s", Prefix, cmd.Name, Review, Summarize, Explain, Ignore)
+	}
+
+	return
+}
+
+var findingID = regexp.MustCompile(`^[0-9a-f]{8}$`)
+
+// Location is the file and the lines a link points to.
+type Location struct {
+	File      string
+	StartLine int
+	EndLine   int
+	// DiffHash is set instead of File for the links to the files tab of a
+	// pull request, which identify files by the SHA-256 of their name.
+	DiffHash string
+}
+
+var (
+	// https://github.com/owner/repo/blob/<ref>/path/to/file.go#L10-L12
+	blobLink = regexp.MustCompile(`^https?://[^/]+/[^/]+/[^/]+/blob/[^/]+/([^#]+)#L(\d+)(?:-L(\d+))?$`)
+	// https://github.com/owner/repo/pull/1/files#diff-<sha256>R10-R12
+	diffLink = regexp.MustCompile(`#diff-([0-9a-f]{64})R(\d+)(?:-R(\d+))?$`)
+	// path/to/file.go:10-12 or path/to/file.go#L10-L12
+	fileLine = regexp.MustCompile(`^([^#:\s]+)(?::|#L)(\d+)(?:-L?(\d+))?$`)
+)
+
+// ParseLocation parses the argument of the explain command: a link to lines
+// of a file of the repository or of the pull request, or "file:line".
+func ParseLocation(arg string) (loc Location, err error) {
+	var match []string
+
+	arg = strings.Trim(arg, "<>")
+
+	switch {
+	case blobLink.MatchString(arg):
+		match = blobLink.FindStringSubmatch(arg)
+		loc.File = match[1]
+	case diffLink.MatchString(arg):
+		match = diffLink.FindStringSubmatch(arg)
+		loc.DiffHash = match[1]
+	case fileLine.MatchString(arg):
+		match = fileLine.FindStringSubmatch(arg)
+		loc.File = match[1]
+	default:
+		return loc, fmt.Errorf("%q is not a link to lines of a file nor file:line", arg)
+	}
+
+	loc.StartLine, _ = strconv.Atoi(match[2])
+	loc.EndLine = loc.StartLine
+	if match[3] != "" {
+		loc.EndLine, _ = strconv.Atoi(match[3])
+	}
+
+	if loc.EndLine < loc.StartLine {
+		loc.StartLine, loc.EndLine = loc.EndLine, loc.StartLine
+	}
+
+	return
+}
+
+// IgnoredMarker is a hidden HTML comment that identifies the comment of a
+// pull request listing the findings ignored with the ignore command.
+const IgnoredMarker = "<!-- powerpr:ignored -->"
+
+var ignoredID = regexp.MustCompile("(?m)^- `([0-9a-f]{8})`")
+
+// Ignored returns the IDs of the findings listed in the body of the ignored
+// findings comment.
+func Ignored(body string) (ids []string) {
+	for _, match := range ignoredID.FindAllStringSubmatch(body, -1) {
+		ids = append(ids, match[1])
+	}
+
+	return
+}
+
+// IgnoredBy returns the IDs of the findings listed in the last ignored
+// findings comment of posts written by login, the account of the bot. The
+// comments of anyone else starting with IgnoredMarker are not trusted, so they
+// can not hide findings from the review.
+func IgnoredBy(posts []content.Post, login string) (ids []string) {
+	for _, post := range posts {
+		if login != "" && strings.HasPrefix(post.Body, IgnoredMarker) && strings.EqualFold(post.Author, login) {
+			ids = Ignored(post.Body)
+		}
+	}
+
+	return
+}
+
+// IgnoredComment returns the body of the comment listing the ignored
+// findings, starting with IgnoredMarker.
+func IgnoredComment(ids []string) string {
+	var b strings.Builder
+
+	b.WriteString(IgnoredMarker + "\nThe following findings are left out of the reviews of this pull request:\n\n")
+	for _, id := range ids {
+		fmt.Fprintf(&b, "- `%s`\n", id)
+	}
+
+	return b.String()
+}
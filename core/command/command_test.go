@@ -0,0 +1,106 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+func Test_Parse(t *testing.T) {
+	var tests = []struct {
+		body          string
+		expected      Command
+		found         bool
+		errorExpected bool
+	}{
+		{"looks good", Command{}, false, false},
+		{"/powerprs review", Command{}, false, false},
+		{"/powerpr", Command{Name: Review}, true, false},
+		{"/powerpr review", Command{Name: Review, Args: []string{}}, true, false},
+		{"  /powerpr Review core/a.go core/b.go\nthanks", Command{Name: Review, Args: []string{"core/a.go", "core/b.go"}}, true, false},
+		{"/powerpr summarize", Command{Name: Summarize, Args: []string{}}, true, false},
+		{"/powerpr explain a.go:12", Command{Name: Explain, Args: []string{"a.go:12"}}, true, false},
+		{"/powerpr ignore 1a2b3c4d", Command{Name: Ignore, Args: []string{"1a2b3c4d"}}, true, false},
+		{"/powerpr ignore", Command{Name: Ignore, Args: []string{}}, true, true},
+		{"/powerpr ignore bug", Command{Name: Ignore, Args: []string{"bug"}}, true, true},
+		{"/powerpr deploy", Command{Name: "deploy", Args: []string{}}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			cmd, found, err := Parse(tt.body)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found != tt.found || !reflect.DeepEqual(cmd, tt.expected) {
+				t.Fatalf("expected %+v %v, got %+v %v", tt.expected, tt.found, cmd, found)
+			}
+		})
+	}
+}
+
+func Test_ParseLocation(t *testing.T) {
+	const hash = "5b1fbbd1c4ae24ff8d3e2a3a3b8f5a1e0c8e5a06d8c3e47c1ea6b6f2c1d6f6a9"
+
+	var tests = []struct {
+		arg           string
+		expected      Location
+		errorExpected bool
+	}{
+		{"core/a.go:12", Location{File: "core/a.go", StartLine: 12, EndLine: 12}, false},
+		{"core/a.go#L12-L15", Location{File: "core/a.go", StartLine: 12, EndLine: 15}, false},
+		{"https://github.com/owner/repo/blob/main/core/a.go#L3-L1", Location{File: "core/a.go", StartLine: 1, EndLine: 3}, false},
+		{"<https://github.com/owner/repo/pull/7/files#diff-" + hash + "R20>", Location{DiffHash: hash, StartLine: 20, EndLine: 20}, false},
+		{"core/a.go", Location{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			loc, err := ParseLocation(tt.arg)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && loc != tt.expected {
+				t.Fatalf("expected %+v, got %+v", tt.expected, loc)
+			}
+		})
+	}
+}
+
+func Test_IgnoredComment(t *testing.T) {
+	ids := []string{"1a2b3c4d", "5e6f7a8b"}
+
+	if parsed := Ignored(IgnoredComment(ids)); !reflect.DeepEqual(parsed, ids) {
+		t.Fatalf("expected %v, got %v", ids, parsed)
+	}
+}
+
+func Test_IgnoredBy(t *testing.T) {
+	posts := []content.Post{
+		{Author: "powerpr[bot]", Body: IgnoredComment([]string{"1a2b3c4d"})},
+		{Author: "mallory", Body: IgnoredComment([]string{"5e6f7a8b", "9c0d1e2f"})},
+		{Author: "ana", Body: "/powerpr ignore 1a2b3c4d"},
+	}
+
+	var tests = []struct {
+		name     string
+		login    string
+		expected []string
+	}{
+		{"bot", "powerpr[bot]", []string{"1a2b3c4d"}},
+		{"case of the login", "PowerPR[bot]", []string{"1a2b3c4d"}},
+		{"another author", "ana", nil},
+		{"unknown bot", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := IgnoredBy(posts, tt.login); !reflect.DeepEqual(ids, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
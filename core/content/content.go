@@ -0,0 +1,102 @@
+// Package content holds the typed model of a pull request as it is presented
+// to the model, and the versioned renderer that serializes it into the prompt.
+package content
+
+import "time"
+
+// PullRequest is the root of the content sent to the model.
+type PullRequest struct {
+	Title       string
+	Description string
+	Issues      []Issue
+	Commits     []Commit
+
+	// Conversation holds the most recent posts of the pull request
+	// conversation, and ConversationOmitted counts the older ones that did
+	// not fit in the prompt.
+	Conversation        []Post
+	ConversationOmitted int
+
+	// HeadSHA is the commit the pull request points to. It is not rendered.
+	HeadSHA string
+
+	// Labels are the labels of the pull request, which select the review
+	// profiles. They are not rendered.
+	Labels []string
+}
+
+// Issue is an issue referenced by the pull request. Closing is set when the
+// pull request claims to fix it.
+type Issue struct {
+	Reference string
+	Closing   bool
+	State     string
+	Title     string
+	Labels    []string
+	Body      string
+}
+
+// Kinds of Post.
+const (
+	PostComment = "comment"
+	PostReview  = "review"
+)
+
+// Post is an entry of the pull request conversation: either a comment or the
+// summary body of a review, in which case State is the review state.
+type Post struct {
+	Kind      string
+	Author    string
+	CreatedAt time.Time
+	State     string
+	Body      string
+}
+
+// Commit is a single commit of the pull request with the files it touched.
+type Commit struct {
+	SHA     string
+	Message string
+	Files   []File
+}
+
+// File is a file changed by a commit. PreviousFilename is only set when the
+// file has been renamed.
+type File struct {
+	PreviousFilename string
+	Filename         string
+	Status           string
+	Additions        int
+	Deletions        int
+	Changes          int
+	Hunks            []Hunk
+	Context          []Snippet
+	Comments         []Comment
+}
+
+// Hunk is a single "git diff" hunk of a file patch. Header is the hunk header
+// line and Body holds the diff lines that follow it.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Header   string
+	Body     string
+}
+
+// Snippet is a contiguous range of lines of a file, at the head of the pull
+// request, sent as context for its hunks.
+type Snippet struct {
+	StartLine int
+	EndLine   int
+	Text      string
+}
+
+// Comment is an inline review comment made on a file. Line is the line of
+// the new version of the file it is on, not a position in the diff, and 0
+// when it is on a removed line.
+type Comment struct {
+	Line int
+	User string
+	Body string
+}
@@ -0,0 +1,37 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_EachText(t *testing.T) {
+	const secret = "s3cr3t"
+
+	pr := PullRequest{
+		Title:        secret,
+		Description:  secret,
+		Issues:       []Issue{{Reference: secret, State: secret, Title: secret, Labels: []string{secret}, Body: secret}},
+		Conversation: []Post{{Kind: PostReview, Author: secret, State: secret, Body: secret}},
+		Commits: []Commit{{
+			SHA:     "1111111",
+			Message: secret,
+			Files: []File{{
+				PreviousFilename: "a.go",
+				Filename:         "b.go",
+				Status:           "renamed",
+				Hunks:            []Hunk{{Header: secret}},
+				Context:          []Snippet{{Text: secret}},
+				Comments:         []Comment{{User: secret, Body: secret}},
+			}},
+		}},
+	}
+
+	pr.EachText(func(_ string, text *string) {
+		*text = strings.ReplaceAll(*text, secret, "x")
+	})
+
+	if rendered := Render(pr); strings.Contains(rendered, secret) {
+		t.Fatalf("a rendered text was not visited:\n%s", rendered)
+	}
+}
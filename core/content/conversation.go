@@ -0,0 +1,20 @@
+package content
+
+// LatestPosts keeps the most recent posts of a chronological conversation
+// whose bodies fit within budget tokens, and returns how many older posts
+// were left out.
+func LatestPosts(posts []Post, budget int) (kept []Post, omitted int) {
+	start := len(posts)
+
+	for start > 0 {
+		tokens := EstimateTokens(posts[start-1].Body)
+		if tokens > budget {
+			break
+		}
+
+		budget -= tokens
+		start--
+	}
+
+	return posts[start:], start
+}
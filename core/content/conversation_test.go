@@ -0,0 +1,40 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_LatestPosts(t *testing.T) {
+	var posts = []Post{
+		{Author: "a", Body: strings.Repeat("x", 40)},
+		{Author: "b", Body: strings.Repeat("x", 40)},
+		{Author: "c", Body: strings.Repeat("x", 40)},
+	}
+
+	var tests = []struct {
+		name    string
+		budget  int
+		kept    string
+		omitted int
+	}{
+		{"everything fits", 30, "abc", 0},
+		{"oldest posts are left out", 25, "bc", 1},
+		{"nothing fits", 5, "", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, omitted := LatestPosts(posts, tt.budget)
+
+			var authors string
+			for _, p := range kept {
+				authors += p.Author
+			}
+
+			if authors != tt.kept || omitted != tt.omitted {
+				t.Fatalf("expected %q and %d omitted, got %q and %d omitted", tt.kept, tt.omitted, authors, omitted)
+			}
+		})
+	}
+}
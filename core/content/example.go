@@ -0,0 +1,78 @@
+package content
+
+import "time"
+
+// Example returns a small pull request that exercises every element of the
+// format. It is rendered into the system prompt as an example of the input the
+// model receives, and into the golden files that pin the format down.
+func Example() PullRequest {
+	return PullRequest{
+		Title:       "Add fibonacci helper",
+		Description: "Adds a naive fibonacci implementation and updates the test file.\n\nFixes #7",
+		Issues: []Issue{{
+			Reference: "octocat/example#7",
+			Closing:   true,
+			State:     "open",
+			Title:     "Provide a fibonacci helper",
+			Labels:    []string{"enhancement"},
+			Body:      "We need a function that returns the n-th fibonacci number.",
+		}},
+		Conversation: []Post{{
+			Kind:      PostComment,
+			Author:    "laughing.crab",
+			CreatedAt: time.Date(2024, 4, 20, 10, 0, 0, 0, time.UTC),
+			Body:      "Should we memoize it?",
+		}, {
+			Kind:      PostReview,
+			Author:    "octocat",
+			CreatedAt: time.Date(2024, 4, 20, 11, 30, 0, 0, time.UTC),
+			State:     "COMMENTED",
+			Body:      "Let's keep it naive for now, it is only used in tests.",
+		}},
+		ConversationOmitted: 1,
+		Commits: []Commit{{
+			SHA:     "da31ac609173a56b005f359f03426bb712271cc7",
+			Message: "add fibonacci helper",
+			Files: []File{{
+				PreviousFilename: "test",
+				Filename:         "test.txt",
+				Status:           "renamed",
+				Additions:        3,
+				Deletions:        1,
+				Changes:          4,
+				Hunks: ParsePatch("@@ -1 +1,3 @@\n" +
+					"-THIS IS ONLY A TEST FILE\n" +
+					"\\ No newline at end of file\n" +
+					"+THIS IS ONLY A TEST FILE\n" +
+					"+\n" +
+					"+NEW LINE\n" +
+					"\\ No newline at end of file"),
+				Comments: []Comment{{
+					Line: 3,
+					User: "laughing.crab",
+					Body: "Is this line needed?",
+				}},
+			}, {
+				Filename:  "fibo.py",
+				Status:    "added",
+				Additions: 5,
+				Changes:   5,
+				Hunks: ParsePatch("@@ -0,0 +1,5 @@\n" +
+					"+def fibonacci(n):\n" +
+					"+    if n <= 1:\n" +
+					"+        return n\n" +
+					"+    else:\n" +
+					"+        return fibonacci(n-1) + fibonacci(n-2)"),
+				Context: []Snippet{{
+					StartLine: 1,
+					EndLine:   5,
+					Text: "def fibonacci(n):\n" +
+						"    if n <= 1:\n" +
+						"        return n\n" +
+						"    else:\n" +
+						"        return fibonacci(n-1) + fibonacci(n-2)",
+				}},
+			}},
+		}},
+	}
+}
@@ -0,0 +1,130 @@
+package content
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParsePatch splits a unified diff patch, as returned by the GitHub API for a
+// single file, into its hunks. Lines before the first hunk header are ignored.
+func ParsePatch(patch string) (hunks []Hunk) {
+	var (
+		current *Hunk
+		body    []string
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Body = strings.Join(body, "\n")
+			hunks = append(hunks, *current)
+		}
+	}
+
+	for _, line := range strings.Split(patch, "\n") {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: rangeLength(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: rangeLength(m[4]),
+				Header:   line,
+			}
+			body = nil
+			continue
+		}
+
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return
+}
+
+// PositionLine converts a position in the patch of a file, as given by the
+// GitHub API, to the line of the new version of the file. Position 1 is the
+// line below the first hunk header, and the headers of the following hunks
+// count as positions too. ok is false when the position is out of the patch
+// or on a removed line, which is not in the new version.
+func PositionLine(hunks []Hunk, position int) (line int, ok bool) {
+	for i, hunk := range hunks {
+		if i > 0 {
+			// The header of the hunk
+			if position--; position <= 0 {
+				return 0, false
+			}
+		}
+
+		lines := strings.Split(hunk.Body, "\n")
+		if position > len(lines) {
+			position -= len(lines)
+			continue
+		}
+
+		if position <= 0 || strings.HasPrefix(lines[position-1], "-") || strings.HasPrefix(lines[position-1], "\\") {
+			return 0, false
+		}
+
+		line = hunk.NewStart
+		for _, l := range lines[:position-1] {
+			if !strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "\\") {
+				line++
+			}
+		}
+
+		return line, true
+	}
+
+	return 0, false
+}
+
+// OldLine finds line, of the new version of the file, in the hunks of its
+// patch, and returns the line it was in the old version: 0 when the line was
+// added, and the old line of an unchanged line. ok is false when the line is
+// not in the hunks.
+func OldLine(hunks []Hunk, line int) (oldLine int, ok bool) {
+	for _, hunk := range hunks {
+		old, current := hunk.OldStart, hunk.NewStart
+
+		for _, l := range strings.Split(hunk.Body, "\n") {
+			switch {
+			case l == "", strings.HasPrefix(l, "\\"):
+			case strings.HasPrefix(l, "+"):
+				if current == line {
+					return 0, true
+				}
+				current++
+			case strings.HasPrefix(l, "-"):
+				old++
+			default:
+				if current == line {
+					return old, true
+				}
+				old++
+				current++
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// rangeLength returns the length of a hunk range, which defaults to one when
+// it is omitted from the header.
+func rangeLength(s string) int {
+	if s == "" {
+		return 1
+	}
+
+	return atoi(s)
+}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
@@ -0,0 +1,61 @@
+package content
+
+import "testing"
+
+func Test_PositionLine(t *testing.T) {
+	hunks := ParsePatch("@@ -1,4 +1,5 @@\n a\n-b\n+c\n+d\n e\n@@ -10,2 +11,2 @@\n x\n-y\n+z")
+
+	var tests = []struct {
+		name     string
+		position int
+		line     int
+		ok       bool
+	}{
+		{"first line", 1, 1, true},
+		{"removed line", 2, 0, false},
+		{"added line after a removed one", 3, 2, true},
+		{"context line", 5, 4, true},
+		{"header of the second hunk", 6, 0, false},
+		{"second hunk", 7, 11, true},
+		{"added line of the second hunk", 9, 12, true},
+		{"out of the patch", 10, 0, false},
+		{"no position", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok := PositionLine(hunks, tt.position)
+			if line != tt.line || ok != tt.ok {
+				t.Fatalf("expected line %d (%v), got %d (%v)", tt.line, tt.ok, line, ok)
+			}
+		})
+	}
+}
+
+func Test_OldLine(t *testing.T) {
+	hunks := ParsePatch("@@ -1,4 +1,5 @@\n a\n-b\n+c\n+d\n e\n@@ -10,2 +11,2 @@\n x\n-y\n+z\n\\ No newline at end of file\n")
+
+	var tests = []struct {
+		name    string
+		line    int
+		oldLine int
+		ok      bool
+	}{
+		{"context line", 1, 1, true},
+		{"added line", 2, 0, true},
+		{"context line after a change", 4, 3, true},
+		{"context line of the second hunk", 11, 10, true},
+		{"added line of the second hunk", 12, 0, true},
+		{"between the hunks", 6, 0, false},
+		{"after the hunks", 13, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldLine, ok := OldLine(hunks, tt.line)
+			if oldLine != tt.oldLine || ok != tt.ok {
+				t.Fatalf("expected old line %d (%v), got %d (%v)", tt.oldLine, tt.ok, oldLine, ok)
+			}
+		})
+	}
+}
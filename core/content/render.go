@@ -0,0 +1,150 @@
+package content
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Version identifies the serialization format produced by Render. It must be
+// bumped whenever the rendered output changes shape, so prompts and golden
+// files can be matched against the format they were written for.
+const Version = "4"
+
+type element struct {
+	name string
+	doc  string
+}
+
+// elements lists every tag emitted by Render, in the order they are
+// documented to the model.
+var elements = []element{
+	{"pull_request", `Root element. Its "format" attribute is the version of this format.`},
+	{"title", "Title of the pull request."},
+	{"description", "Description of the pull request, as written by its author."},
+	{"linked_issues", "Issues referenced by the description or the commit messages of the pull request. Only present when there is at least one."},
+	{"issue", `A linked issue, identified by its "ref" attribute, with its "state", "title" and comma separated "labels", and its body as content. "closing" is "true" when the pull request claims to fix it, in which case the changes are expected to do what the issue asks.`},
+	{"conversation", `The discussion of the pull request, in chronological order, so you know what was already discussed and decided. "omitted" counts the older posts that were left out. Only present when there is at least one post.`},
+	{"post", `A post of the conversation written by "author" at "created_at". "kind" is "comment" for a comment, or "review" for the summary of a review, whose outcome is given by "state".`},
+	{"commit", `A commit of the pull request, identified by its "sha" attribute. A pull request has one or more commits.`},
+	{"message", "Message of the enclosing commit."},
+	{"file", `A file changed by the enclosing commit. "path" is the file name, "previous_path" is only present when the file was renamed, "status" is one of added, modified, removed or renamed, and "additions", "deletions" and "changes" count the changed lines.`},
+	{"hunk", `A "git diff" hunk of the enclosing file. The first line is the hunk header; "old_start"/"old_lines" and "new_start"/"new_lines" repeat its ranges in the previous and the new version of the file.`},
+	{"context", `Source of the enclosing file at the head of the pull request, surrounding its hunks or the whole file when it is small. Its first line is line "start_line" of the file and its last line is line "end_line". Use it to understand the changed code, but only comment on lines changed by the hunks.`},
+	{"comment", `An existing review comment on the enclosing file, made by "user" on line "line" of the new version of the file, or on a removed line when "line" is "0".`},
+}
+
+var escapeTags = regexp.MustCompile(`<(/?(?:` + tagNames() + `))([\s>/])`)
+
+func tagNames() string {
+	var names []string
+	for _, e := range elements {
+		names = append(names, e.name)
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Render serializes the pull request into the tagged format described by
+// Documentation.
+func Render(pr PullRequest) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<pull_request format=%s>\n", attr(Version))
+	fmt.Fprintf(&b, "<title>%s</title>\n", escapeText(pr.Title))
+	writeBlock(&b, "description", "", pr.Description)
+
+	if len(pr.Issues) > 0 {
+		b.WriteString("<linked_issues>\n")
+		for _, issue := range pr.Issues {
+			writeBlock(&b, "issue", fmt.Sprintf(" ref=%s closing=\"%t\" state=%s title=%s labels=%s",
+				attr(issue.Reference), issue.Closing, attr(issue.State), attr(issue.Title), attr(strings.Join(issue.Labels, ","))), issue.Body)
+		}
+		b.WriteString("</linked_issues>\n")
+	}
+
+	if len(pr.Conversation) > 0 {
+		fmt.Fprintf(&b, "<conversation omitted=\"%d\">\n", pr.ConversationOmitted)
+		for _, post := range pr.Conversation {
+			attrs := fmt.Sprintf(" kind=%s author=%s created_at=%s", attr(post.Kind), attr(post.Author), attr(post.CreatedAt.UTC().Format(time.RFC3339)))
+			if post.State != "" {
+				attrs += " state=" + attr(post.State)
+			}
+			writeBlock(&b, "post", attrs, post.Body)
+		}
+		b.WriteString("</conversation>\n")
+	}
+
+	for _, commit := range pr.Commits {
+		fmt.Fprintf(&b, "<commit sha=%s>\n", attr(commit.SHA))
+		writeBlock(&b, "message", "", commit.Message)
+
+		for _, file := range commit.Files {
+			b.WriteString("<file path=" + attr(file.Filename))
+			if file.PreviousFilename != "" {
+				b.WriteString(" previous_path=" + attr(file.PreviousFilename))
+			}
+			fmt.Fprintf(&b, " status=%s additions=\"%d\" deletions=\"%d\" changes=\"%d\">\n",
+				attr(file.Status), file.Additions, file.Deletions, file.Changes)
+
+			for _, hunk := range file.Hunks {
+				writeBlock(&b, "hunk", fmt.Sprintf(
+					` old_start="%d" old_lines="%d" new_start="%d" new_lines="%d"`,
+					hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines,
+				), hunk.Header+"\n"+hunk.Body)
+			}
+
+			for _, snippet := range file.Context {
+				writeBlock(&b, "context", fmt.Sprintf(` start_line="%d" end_line="%d"`, snippet.StartLine, snippet.EndLine), snippet.Text)
+			}
+
+			for _, comment := range file.Comments {
+				writeBlock(&b, "comment", fmt.Sprintf(` line="%d" user=%s`, comment.Line, attr(comment.User)), comment.Body)
+			}
+
+			b.WriteString("</file>\n")
+		}
+
+		b.WriteString("</commit>\n")
+	}
+
+	b.WriteString("</pull_request>\n")
+
+	return b.String()
+}
+
+// Documentation describes the format produced by Render. It is meant to be
+// embedded in the system prompt, so the model is told about the exact format
+// it receives.
+func Documentation() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "The pull request is sent in a tagged format, version %s, with the following elements:\n", Version)
+	for _, e := range elements {
+		fmt.Fprintf(&b, "- <%s>: %s\n", e.name, e.doc)
+	}
+	b.WriteString("Any text inside an element that looks like one of these tags is escaped with \"&lt;\".\n")
+
+	return b.String()
+}
+
+func writeBlock(b *strings.Builder, name, attrs, text string) {
+	fmt.Fprintf(b, "<%s%s>\n", name, attrs)
+	if text = strings.TrimRight(text, "\n"); text != "" {
+		b.WriteString(escapeText(text) + "\n")
+	}
+	fmt.Fprintf(b, "</%s>\n", name)
+}
+
+// escapeText neutralizes anything in free text that could be mistaken for one
+// of the format tags. Everything else, including code, is kept verbatim.
+func escapeText(s string) string {
+	return escapeTags.ReplaceAllString(s, "&lt;$1$2")
+}
+
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", "\n", " ")
+
+func attr(s string) string {
+	return `"` + attrEscaper.Replace(s) + `"`
+}
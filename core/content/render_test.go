@@ -0,0 +1,98 @@
+package content
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != string(want) {
+		t.Fatalf("%s does not match the rendered output, run the tests with -update if the change is intended\ngot:\n%s", path, got)
+	}
+}
+
+func Test_Render(t *testing.T) {
+	var tests = []struct {
+		name   string
+		golden string
+		pr     PullRequest
+	}{
+		{
+			"example",
+			"example.golden",
+			Example(),
+		},
+		{
+			"escape tags in text",
+			"escape.golden",
+			PullRequest{
+				Title:       `Close </title> early & "quote"`,
+				Description: "Fake tags: <commit sha=\"x\"> and </pull_request>\nGenerics stay: List<T>",
+				Commits: []Commit{{
+					SHA:     "abc",
+					Message: "touch <file path=\"a\">",
+					Files: []File{{
+						Filename: `we"ird.go`,
+						Status:   "modified",
+						Hunks:    ParsePatch("@@ -1,2 +1,2 @@\n-if a < b {\n+if a <= b {\n </hunk>"),
+					}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden(t, tt.golden, Render(tt.pr))
+		})
+	}
+}
+
+func Test_Documentation(t *testing.T) {
+	golden(t, "documentation.golden", Documentation())
+}
+
+func Test_ParsePatch(t *testing.T) {
+	hunks := ParsePatch("@@ -1 +1,3 @@ func main() {\n-a\n+b\n+c\n+d\n@@ -10,0 +12,2 @@\n+e\n+f")
+
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+
+	var tests = []struct {
+		hunk                                   Hunk
+		oldStart, oldLines, newStart, newLines int
+		body                                   string
+	}{
+		{hunks[0], 1, 1, 1, 3, "-a\n+b\n+c\n+d"},
+		{hunks[1], 10, 0, 12, 2, "+e\n+f"},
+	}
+
+	for _, tt := range tests {
+		h := tt.hunk
+		if h.OldStart != tt.oldStart || h.OldLines != tt.oldLines || h.NewStart != tt.newStart || h.NewLines != tt.newLines {
+			t.Fatalf("unexpected ranges for %q: %+v", h.Header, h)
+		}
+		if h.Body != tt.body {
+			t.Fatalf("unexpected body for %q: %q", h.Header, h.Body)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package content
+
+import "fmt"
+
+// EachText calls fn with a pointer to every text of pr that Render writes, and
+// the name of the part of the pull request it belongs to, so fn can rewrite
+// it. The bodies of the hunks are left out: they are the changes themselves,
+// which are handled line by line. So are the paths of the files, which the
+// findings are posted back to, and the SHAs, dates, kinds and statuses, which
+// are set by the code host.
+func (pr *PullRequest) EachText(fn func(name string, text *string)) {
+	fn("title", &pr.Title)
+	fn("description", &pr.Description)
+
+	for i := range pr.Issues {
+		issue := &pr.Issues[i]
+		name := fmt.Sprintf("issue %s", issue.Reference)
+
+		fn(name, &issue.Reference)
+		fn(name, &issue.State)
+		fn(name, &issue.Title)
+		for j := range issue.Labels {
+			fn(name, &issue.Labels[j])
+		}
+		fn(name, &issue.Body)
+	}
+
+	for i := range pr.Conversation {
+		post := &pr.Conversation[i]
+		name := fmt.Sprintf("%s of %s", post.Kind, post.Author)
+
+		fn(name, &post.Author)
+		fn(name, &post.State)
+		fn(name, &post.Body)
+	}
+
+	for i := range pr.Commits {
+		commit := &pr.Commits[i]
+
+		fn(fmt.Sprintf("message of commit %s", commit.SHA), &commit.Message)
+
+		name := fmt.Sprintf("files of commit %s", commit.SHA)
+		for j := range commit.Files {
+			file := &commit.Files[j]
+
+			for k := range file.Hunks {
+				fn(name, &file.Hunks[k].Header)
+			}
+			for k := range file.Context {
+				fn(name, &file.Context[k].Text)
+			}
+			for k := range file.Comments {
+				fn(name, &file.Comments[k].User)
+				fn(name, &file.Comments[k].Body)
+			}
+		}
+	}
+}
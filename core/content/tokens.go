@@ -0,0 +1,8 @@
+package content
+
+// EstimateTokens approximates the number of model tokens of a text. It uses
+// the common rule of thumb of four characters per token, which is close
+// enough to keep the prompt within a budget without shipping a tokenizer.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
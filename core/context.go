@@ -0,0 +1,56 @@
+package core
+
+import (
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/snippet"
+	"go.uber.org/zap"
+)
+
+// addSourceContext attaches to the changed files the source surrounding their
+// hunks, fetched at the head of the pull request, for as long as the prompt
+// stays within cfg.MaxPromptTokens.
+//
+// Only the most recent revision of each file receives context: its hunk line
+// numbers are the ones that match the file at the head SHA.
+func addSourceContext(cfg config.Config, pr *content.PullRequest) {
+	var (
+		seen   = make(map[string]bool)
+		budget = cfg.MaxPromptTokens - content.EstimateTokens(content.Render(*pr))
+	)
+
+	for i := len(pr.Commits) - 1; i >= 0; i-- {
+		for j := range pr.Commits[i].Files {
+			file := &pr.Commits[i].Files[j]
+			if seen[file.Filename] {
+				continue
+			}
+			seen[file.Filename] = true
+
+			if file.Status == "removed" || len(file.Hunks) == 0 {
+				continue
+			}
+
+			source, err := config.EnvSingletons.CodeHost.GetFileContent(
+				cfg.GithubRepoOwner,
+				cfg.GithubRepoName,
+				file.Filename,
+				pr.HeadSHA,
+			)
+			if err != nil {
+				config.EnvSingletons.Logger.Warn("Skipping the context of a file", zap.String("file", file.Filename), zap.Error(err))
+				continue
+			}
+
+			for _, s := range snippet.Context(file.Filename, source, file.Hunks, cfg.ContextLines, cfg.SmallFileLines) {
+				tokens := content.EstimateTokens(s.Text)
+				if tokens > budget {
+					continue
+				}
+
+				file.Context = append(file.Context, s)
+				budget -= tokens
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/command"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"go.uber.org/zap"
+)
+
+// addConversation attaches the most recent posts of the pull request
+// conversation that fit within cfg.ConversationTokens and what is
+// left of the prompt budget, and returns the IDs of the findings ignored in
+// the comment of the bot. The conversation is optional context: on error, the
+// pull request is reviewed without it.
+func addConversation(cfg config.Config, prr github.PullRequestReviewRequest, pr *content.PullRequest) (ignored []string, err error) {
+	var (
+		posts []content.Post
+		login string
+	)
+
+	if posts, err = config.EnvSingletons.CodeHost.GetConversation(prr); err != nil {
+		return
+	}
+
+	// Without the login of the bot no comment can be trusted, so no finding
+	// is ignored
+	if login, err = config.EnvSingletons.CodeHost.Login(); err != nil {
+		config.EnvSingletons.Logger.Warn("Error to get the login of the bot, no finding is ignored", zap.Error(err))
+		err = nil
+	}
+
+	ignored = command.IgnoredBy(posts, login)
+
+	// The walkthrough describes the pull request itself, and the ignored
+	// findings only matter once the review is done: neither is part of the
+	// discussion.
+	for i := 0; i < len(posts); i++ {
+		if strings.HasPrefix(posts[i].Body, github.WalkthroughMarker) || strings.HasPrefix(posts[i].Body, command.IgnoredMarker) {
+			posts = append(posts[:i], posts[i+1:]...)
+			i--
+		}
+	}
+
+	budget := cfg.MaxPromptTokens - content.EstimateTokens(content.Render(*pr))
+	if budget > cfg.ConversationTokens {
+		budget = cfg.ConversationTokens
+	}
+
+	pr.Conversation, pr.ConversationOmitted = content.LatestPosts(posts, budget)
+
+	return
+}
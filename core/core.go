@@ -1,56 +1,68 @@
 package core
 
 import (
-	"encoding/json"
-	"strings"
+	"os"
 
 	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/failure"
 	"github.com/lucasmbaia/power-actions/core/github"
-	"github.com/lucasmbaia/power-actions/core/openai"
+	"github.com/lucasmbaia/power-actions/core/profile"
 	"github.com/lucasmbaia/power-actions/core/prompt"
+	"github.com/lucasmbaia/power-actions/core/secrets"
+	"github.com/lucasmbaia/power-actions/core/verify"
+	"go.uber.org/zap"
 )
 
-func Run() (err error) {
+// Run reviews the pull request of config.EnvConfig, or runs the command of
+// the comment that triggered the run.
+func Run() error {
+	return Handle(config.EnvConfig)
+}
+
+// Review reviews the pull request of cfg, which lets several pull requests be
+// reviewed at the same time, each with its own configuration.
+func Review(cfg config.Config) (err error) {
 	var (
-		chatCompletion     openai.ChatCompletionRequest
-		chatResponse       openai.ChatCompletionResponse
-		contentPullRequest string
-		reviews            github.Reviews
-		prr                github.PullRequestReviewRequest
+		p        prepared
+		reviews  github.Reviews
+		verdicts []verify.Verdict
 	)
 
-	prr = github.PullRequestReviewRequest{
-		Owner:           config.EnvConfig.GithubRepoOwner,
-		Repo:            config.EnvConfig.GithubRepoName,
-		PrNumber:        config.EnvConfig.GithubPrNumber,
-		MaxChangedLines: config.EnvConfig.MaxChangedLines,
-	}
-
-	if contentPullRequest, err = config.EnvSingletons.GithubClient.GetPullRequestChanges(prr); err != nil {
+	if p, err = prepare(&cfg); err != nil {
 		return
 	}
 
-	chatCompletion = openai.ChatCompletionRequest{
-		Model: config.EnvConfig.OpenaiModel,
-		Messages: []openai.ChatMessages{{
-			Role:    "system",
-			Content: prompt.INITIAL_PROMPT,
-		}, {
-			Role:    "user",
-			Content: contentPullRequest,
-		}},
-		Temperature: 0.5,
+	prr, pullRequest, selection := p.prr, p.pullRequest, p.selection
+
+	renderedPullRequest, leaks := payload(cfg, &pullRequest)
+
+	// A dry run prints only the findings, so it has no walkthrough
+	if cfg.Walkthrough && !cfg.DryRun {
+		if err := postWalkthrough(cfg, prr, renderedPullRequest); err != nil {
+			config.EnvSingletons.Logger.Warn("Error to post the walkthrough", zap.Error(err))
+		}
 	}
 
-	if chatResponse, err = config.EnvSingletons.OpenaiClient.CreateChatCompletion(chatCompletion); err != nil {
+	if err = complete(cfg, prompt.ReviewPrompt(selection.Focus()), renderedPullRequest, &reviews); err != nil {
 		return
 	}
 
-	reviewStr := strings.Replace(chatResponse.Choices[0].Message.Content, "```json", "", 1)
-	reviewStr = strings.Replace(reviewStr, "```", "", 1)
+	if cfg.Verify {
+		reviews, verdicts = verifyReviews(cfg, pullRequest, reviews)
+	}
 
-	if err = json.Unmarshal([]byte(reviewStr), &reviews); err != nil {
-		return
+	reviews = filterSeverity(reviews, selection.Severity())
+	reviews = dropIgnored(reviews, p.ignored)
+	// Leaked secrets can not be ignored
+	reviews.Review = append(reviews.Review, secrets.Reviews(leaks)...)
+
+	if cfg.DryRun {
+		if err = printDryRun(os.Stdout, reviews, verdicts); err != nil {
+			return
+		}
+
+		return gate(cfg, reviews)
 	}
 
 	if len(reviews.Review) > 0 {
@@ -59,8 +71,113 @@ func Run() (err error) {
 		prr.Comment = "While reviewing the proposed modifications, I did not identify any improvements to be made. Good job."
 	}
 
+	if summary := secrets.Summary(leaks); summary != "" {
+		prr.Comment += "\n\n" + summary
+	}
+
+	if len(selection) > 0 {
+		prr.Comment += "\n\nReview profile: " + selection.Name() + "."
+	}
+
 	prr.Reviews = reviews
-	err = config.EnvSingletons.GithubClient.PullRequestReview(prr)
+	prr.Model = cfg.OpenaiModel
+
+	if err = publish(cfg, prr, pullRequest); err != nil {
+		return
+	}
+
+	return gate(cfg, reviews)
+}
+
+// gate fails the review when one of the findings with a comment, the ones
+// publish posts, is at least as severe as cfg.FailOn, so a CI job can block
+// the merge on it. The findings the code host does not take on the lines of
+// the diff fail the publish before the gate, and the check and SARIF outputs
+// take them all, so they are counted too.
+func gate(cfg config.Config, reviews github.Reviews) error {
+	var count int
+
+	if cfg.FailOn == "" {
+		return nil
+	}
+
+	for _, review := range reviews.Review {
+		if review.Commentable() && review.Severity.AtLeast(cfg.FailOn) {
+			count++
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	return failure.Errorf(failure.Findings, "%d of the findings are %s or more severe", count, cfg.FailOn)
+}
+
+// prepared is a pull request ready to be sent to the model.
+type prepared struct {
+	prr         github.PullRequestReviewRequest
+	pullRequest content.PullRequest
+	selection   profile.Selection
+
+	// ignored are the IDs of the findings ignored with a command.
+	ignored []string
+}
+
+// prepare fetches the pull request of cfg with everything sent to the model:
+// its linked issues, its conversation and the source surrounding its
+// changes. The review profiles selected by its labels are applied to cfg.
+// The pull request is raw: it goes through payload before it is sent.
+func prepare(cfg *config.Config) (p prepared, err error) {
+	p.prr = github.PullRequestReviewRequest{
+		Owner:           cfg.GithubRepoOwner,
+		Repo:            cfg.GithubRepoName,
+		PrNumber:        cfg.GithubPrNumber,
+		MaxChangedLines: cfg.MaxChangedLines,
+		FetchWorkers:    cfg.FetchWorkers,
+	}
+
+	if p.pullRequest, err = config.EnvSingletons.CodeHost.GetPullRequestChanges(p.prr); err != nil {
+		err = failure.Wrap(failure.CodeHost, err)
+		return
+	}
+
+	p.selection = applyProfiles(cfg, &p.pullRequest)
+
+	if p.pullRequest.Issues, err = config.EnvSingletons.CodeHost.GetLinkedIssues(p.prr, p.pullRequest); err != nil {
+		config.EnvSingletons.Logger.Warn("Error to fetch the linked issues", zap.Error(err))
+		err = nil
+	}
+
+	if p.ignored, err = addConversation(*cfg, p.prr, &p.pullRequest); err != nil {
+		config.EnvSingletons.Logger.Warn("Error to fetch the conversation", zap.Error(err))
+		err = nil
+	}
+
+	addSourceContext(*cfg, &p.pullRequest)
 
 	return
 }
+
+// payload applies the data-governance policy to pr, which also redacts its
+// secrets, and renders it for the model. Every pull request and excerpt sent
+// to the model is built by it, so none escapes the policy. It returns the
+// secrets added by the commits of pr, and logs what was withheld for the
+// audit.
+func payload(cfg config.Config, pr *content.PullRequest) (rendered string, leaks []secrets.Leak) {
+	withheld := cfg.Policy.Apply(pr)
+
+	if !withheld.Empty() {
+		config.EnvSingletons.Logger.Info("Withheld content from the model by the data-governance policy",
+			zap.String("repository", cfg.GithubRepoOwner+"/"+cfg.GithubRepoName),
+			zap.Int("pr", cfg.GithubPrNumber),
+			zap.String("withheld", withheld.String()),
+		)
+	}
+
+	if len(withheld.Leaks) > 0 {
+		config.EnvSingletons.Logger.Warn("Redacted the secrets added by the pull request", zap.Int("pr", cfg.GithubPrNumber), zap.Int("count", len(withheld.Leaks)))
+	}
+
+	return content.Render(*pr), withheld.Leaks
+}
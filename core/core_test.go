@@ -8,5 +8,8 @@ import (
 
 func Test_Run(t *testing.T) {
 	config.LoadSingletons()
+	if err := config.LoadPullRequest(); err != nil {
+		t.Fatal(err)
+	}
 	Run()
 }
@@ -0,0 +1,118 @@
+// Package failure classifies the errors of the commands, so each kind of
+// failure exits with its own code and a broken run never looks green in CI.
+package failure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is the kind of a failure.
+type Kind int
+
+// Kinds of the failures.
+const (
+	// Config is an invalid configuration: environment variables, flags,
+	// configuration file or the event of the run.
+	Config Kind = iota + 1
+	// CodeHost is a failure of the API of GitHub or GitLab.
+	CodeHost
+	// LLM is a failure of the model provider.
+	LLM
+	// Parse is an answer of the model or a command that can not be parsed.
+	Parse
+	// Findings is a review whose posted findings reach the severity of
+	// --fail-on. The review itself succeeded.
+	Findings
+)
+
+// Exit codes of the commands.
+const (
+	ExitOK         = 0
+	ExitUnexpected = 1
+	ExitConfig     = 2
+	ExitCodeHost   = 3
+	ExitLLM        = 4
+	ExitParse      = 5
+	ExitFindings   = 6
+)
+
+var exitCodes = map[Kind]int{
+	Config:   ExitConfig,
+	CodeHost: ExitCodeHost,
+	LLM:      ExitLLM,
+	Parse:    ExitParse,
+	Findings: ExitFindings,
+}
+
+var names = map[Kind]string{
+	Config:   "configuration",
+	CodeHost: "code host",
+	LLM:      "LLM",
+	Parse:    "parse",
+	Findings: "findings",
+}
+
+func (k Kind) String() string {
+	if name, ok := names[k]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("kind %d", int(k))
+}
+
+// Error is an error of a known Kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns err as a failure of kind. An error that already has a kind
+// keeps it, as the innermost classification is the most precise. Wrap
+// returns nil when err is nil.
+func Wrap(kind Kind, err error) error {
+	var typed *Error
+
+	if err == nil || errors.As(err, &typed) {
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
+
+// Errorf returns a failure of kind formatted as fmt.Errorf.
+func Errorf(kind Kind, format string, args ...interface{}) error {
+	return Wrap(kind, fmt.Errorf(format, args...))
+}
+
+// KindOf returns the kind of err, or 0 when it has none.
+func KindOf(err error) Kind {
+	var typed *Error
+
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+
+	return 0
+}
+
+// ExitCode returns the exit code of a command that returned err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	if code, ok := exitCodes[KindOf(err)]; ok {
+		return code
+	}
+
+	return ExitUnexpected
+}
@@ -0,0 +1,44 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_ExitCode(t *testing.T) {
+	var tests = []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, ExitOK},
+		{"unclassified", errors.New("boom"), ExitUnexpected},
+		{"config", Errorf(Config, "MAX_CHANGED_LINES need to be a positive integer"), ExitConfig},
+		{"code host", Wrap(CodeHost, errors.New("502 Bad Gateway")), ExitCodeHost},
+		{"llm", Wrap(LLM, errors.New("429 Too Many Requests")), ExitLLM},
+		{"parse", Wrap(Parse, errors.New("invalid character")), ExitParse},
+		{"findings", Errorf(Findings, "2 of the findings are high or more severe"), ExitFindings},
+		{"wrapped by fmt", fmt.Errorf("reviewing: %w", Wrap(LLM, errors.New("timeout"))), ExitLLM},
+		{"innermost kind wins", Wrap(CodeHost, Wrap(Parse, errors.New("invalid character"))), ExitParse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ExitCode(tt.err); code != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func Test_Wrap(t *testing.T) {
+	if err := Wrap(Config, nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	cause := errors.New("boom")
+	if err := Wrap(LLM, cause); !errors.Is(err, cause) || err.Error() != "boom" {
+		t.Fatalf("expected the cause to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/request"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// jwtLifetime is the lifetime of the JWTs signed for the app. GitHub
+	// rejects JWTs that expire more than ten minutes in the future.
+	jwtLifetime = 9 * time.Minute
+
+	// tokenRefresh is how long before their expiry installation tokens are
+	// refreshed, so a token never expires in the middle of a review.
+	tokenRefresh = 5 * time.Minute
+)
+
+type AppConfig struct {
+	ID         int64
+	PrivateKey []byte
+
+	// InstallationID, when set, is used for every request. Otherwise the
+	// installation is looked up from the repository of each request.
+	InstallationID int64
+
+	// BaseURL is the URL of the REST API, https://api.github.com/ when empty.
+	BaseURL string
+}
+
+// App authenticates as a GitHub App installation. It signs JWTs with the
+// private key of the app, exchanges them for installation access tokens, and
+// caches the tokens until shortly before they expire.
+type App struct {
+	id             int64
+	key            *rsa.PrivateKey
+	installationID int64
+	baseURL        string
+	httpClient     *request.Client
+
+	mu            sync.Mutex
+	installations map[string]int64
+	tokens        map[int64]oauth2.TokenSource
+}
+
+func NewApp(cfg AppConfig) (a *App, err error) {
+	a = &App{
+		id:             cfg.ID,
+		installationID: cfg.InstallationID,
+		baseURL:        strings.TrimSuffix(cfg.BaseURL, "/"),
+		installations:  make(map[string]int64),
+		tokens:         make(map[int64]oauth2.TokenSource),
+	}
+
+	if cfg.ID == 0 {
+		return nil, fmt.Errorf("you must inform the app id")
+	}
+
+	if a.baseURL == "" {
+		a.baseURL = "https://api.github.com"
+	}
+
+	if a.key, err = parsePrivateKey(cfg.PrivateKey); err != nil {
+		return nil, err
+	}
+
+	if a.httpClient, err = request.NewClient(request.ClientConfiguration{}); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("the app private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the app private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// JWT returns a JSON Web Token that authenticates as the app itself.
+func (a *App) JWT() (string, error) {
+	now := time.Now()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		// Backdated to allow for clock drift between us and GitHub
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": a.id,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Installation returns the installation of the app on a repository, or the
+// configured installation when there is one.
+func (a *App) Installation(owner, repo string) (id int64, err error) {
+	if a.installationID != 0 {
+		return a.installationID, nil
+	}
+
+	key := strings.ToLower(owner + "/" + repo)
+
+	a.mu.Lock()
+	id, ok := a.installations[key]
+	a.mu.Unlock()
+	if ok {
+		return
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+
+	if err = a.appRequest(request.GET, fmt.Sprintf("/repos/%s/%s/installation", owner, repo), http.StatusOK, &installation); err != nil {
+		return 0, fmt.Errorf("finding the app installation of %s/%s: %w", owner, repo, err)
+	}
+
+	a.mu.Lock()
+	a.installations[key] = installation.ID
+	a.mu.Unlock()
+
+	return installation.ID, nil
+}
+
+// TokenSource returns the source of the access tokens of an installation.
+// Tokens are reused until tokenRefresh before their expiry.
+func (a *App) TokenSource(installationID int64) oauth2.TokenSource {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ts, ok := a.tokens[installationID]
+	if !ok {
+		ts = oauth2.ReuseTokenSourceWithExpiry(nil, installationTokenSource{app: a, installationID: installationID}, tokenRefresh)
+		a.tokens[installationID] = ts
+	}
+
+	return ts
+}
+
+type installationTokenSource struct {
+	app            *App
+	installationID int64
+}
+
+func (s installationTokenSource) Token() (*oauth2.Token, error) {
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	if err := s.app.appRequest(request.POST, fmt.Sprintf("/app/installations/%d/access_tokens", s.installationID), http.StatusCreated, &token); err != nil {
+		return nil, fmt.Errorf("creating an access token of installation %d: %w", s.installationID, err)
+	}
+
+	return &oauth2.Token{AccessToken: token.Token, TokenType: "token", Expiry: token.ExpiresAt}, nil
+}
+
+// appRequest sends a request authenticated as the app and decodes its
+// response into v.
+func (a *App) appRequest(method, path string, expectedCode int, v interface{}) (err error) {
+	var (
+		jwt      string
+		response request.Response
+	)
+
+	if jwt, err = a.JWT(); err != nil {
+		return
+	}
+
+	if response, err = a.httpClient.Request(method, a.baseURL+path, request.Options{
+		Headers: map[string]string{
+			"Authorization": "Bearer " + jwt,
+			"Accept":        "application/vnd.github+json",
+		},
+	}); err != nil {
+		return
+	}
+
+	if response.Code != expectedCode {
+		return fmt.Errorf("unexpected status %d: %s", response.Code, string(response.Body))
+	}
+
+	return json.Unmarshal(response.Body, v)
+}
+
+// Transport returns a round tripper that authenticates each request with a
+// token of the installation of the app on the repository the request is
+// about, or of the configured installation.
+func (a *App) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &appTransport{app: a, base: base}
+}
+
+type appTransport struct {
+	app  *App
+	base http.RoundTripper
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var installationID = t.app.installationID
+
+	if installationID == 0 {
+		owner, repo, ok := repositoryOf(req.URL.Path)
+		if !ok {
+			return nil, fmt.Errorf("can not tell the app installation of %s, set the installation id", req.URL.Path)
+		}
+
+		var err error
+		if installationID, err = t.app.Installation(owner, repo); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := t.app.TokenSource(installationID).Token()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	token.SetAuthHeader(req)
+
+	return t.base.RoundTrip(req)
+}
+
+// repositoryOf returns the repository of an API path such as
+// "/repos/owner/repo/pulls/1", or "/api/v3/repos/owner/repo" on GitHub
+// Enterprise Server.
+func repositoryOf(path string) (owner, repo string, ok bool) {
+	_, rest, found := strings.Cut(path, "/repos/")
+	if !found {
+		return
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return
+	}
+
+	return parts[0], parts[1], true
+}
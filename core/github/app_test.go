@@ -0,0 +1,126 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_App(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var (
+		tokens     int32
+		lookups    int32
+		authorized []string
+	)
+
+	verifyJWT := func(t *testing.T, r *http.Request) {
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Fatalf("invalid JWT %q", jwt)
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Fatalf("invalid JWT signature: %v", err)
+		}
+
+		var claims struct {
+			Iss int64 `json:"iss"`
+			Exp int64 `json:"exp"`
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(payload, &claims)
+		if claims.Iss != 7 || time.Until(time.Unix(claims.Exp, 0)) > 10*time.Minute {
+			t.Fatalf("invalid JWT claims: %s", payload)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/installation":
+			verifyJWT(t, r)
+			atomic.AddInt32(&lookups, 1)
+			fmt.Fprint(w, `{"id": 42}`)
+		case "/app/installations/42/access_tokens":
+			verifyJWT(t, r)
+			n := atomic.AddInt32(&tokens, 1)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`, n, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			authorized = append(authorized, r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	app, err := NewApp(AppConfig{ID: 7, PrivateKey: privateKey, BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: app.Transport(nil)}
+	for _, path := range []string{"/repos/owner/repo/pulls/1", "/repos/Owner/Repo/issues/1"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if lookups != 1 || tokens != 1 {
+		t.Fatalf("expected the installation and its token to be cached, got %d lookups and %d tokens", lookups, tokens)
+	}
+
+	for _, header := range authorized {
+		if header != "token token-1" {
+			t.Fatalf("unexpected authorization %q", header)
+		}
+	}
+
+	if _, err := client.Get(server.URL + "/user"); err == nil {
+		t.Fatal("expected an error for a request outside of a repository")
+	}
+}
+
+func Test_RepositoryOf(t *testing.T) {
+	var tests = []struct {
+		path  string
+		owner string
+		repo  string
+		ok    bool
+	}{
+		{"/repos/owner/repo/pulls/1", "owner", "repo", true},
+		{"/api/v3/repos/owner/repo", "owner", "repo", true},
+		{"/repos/owner", "", "", false},
+		{"/graphql", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			owner, repo, ok := repositoryOf(tt.path)
+			if owner != tt.owner || repo != tt.repo || ok != tt.ok {
+				t.Fatalf("expected %s/%s %t, got %s/%s %t", tt.owner, tt.repo, tt.ok, owner, repo, ok)
+			}
+		})
+	}
+}
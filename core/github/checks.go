@@ -0,0 +1,198 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+const (
+	// CheckRunName is the name of the check run created for the findings.
+	CheckRunName = "powerpr"
+
+	// maxAnnotations is the maximum number of annotations the API accepts in
+	// a single create or update request of a check run.
+	maxAnnotations = 50
+)
+
+// CheckRunRequest describes the check run that publishes the findings of a
+// review. A finding at least as serious as FailureSeverity concludes the check
+// run as a failure, and otherwise one at least as serious as NeutralSeverity
+// concludes it as neutral. An empty threshold is never reached.
+type CheckRunRequest struct {
+	HeadSHA         string
+	FailureSeverity Severity
+	NeutralSeverity Severity
+}
+
+// CreateCheckRun publishes the reviews of prr as a check run on the head SHA,
+// one annotation per finding, with prr.Comment as the summary of its output.
+func (c *Client) CreateCheckRun(prr PullRequestReviewRequest, cr CheckRunRequest) (err error) {
+	var (
+		checkRun    *gogithub.CheckRun
+		annotations = newAnnotations(prr.Reviews)
+		conclusion  = Conclusion(prr.Reviews, cr.FailureSeverity, cr.NeutralSeverity)
+		batches     = batchAnnotations(annotations)
+	)
+
+	output := func(batch []*gogithub.CheckRunAnnotation) *gogithub.CheckRunOutput {
+		return &gogithub.CheckRunOutput{
+			Title:       gogithub.String(checkRunTitle(prr.Reviews)),
+			Summary:     gogithub.String(prr.Comment),
+			Annotations: batch,
+		}
+	}
+
+	opts := gogithub.CreateCheckRunOptions{
+		Name:    CheckRunName,
+		HeadSHA: cr.HeadSHA,
+		Status:  gogithub.String("in_progress"),
+		Output:  output(batches[0]),
+	}
+
+	if len(batches) == 1 {
+		opts.Status = gogithub.String("completed")
+		opts.Conclusion = gogithub.String(conclusion)
+		opts.CompletedAt = &gogithub.Timestamp{Time: time.Now()}
+	}
+
+	if checkRun, _, err = c.Client.Checks.CreateCheckRun(c.ctx, prr.Owner, prr.Repo, opts); err != nil {
+		return
+	}
+
+	for i := 1; i < len(batches); i++ {
+		update := gogithub.UpdateCheckRunOptions{
+			Name:   CheckRunName,
+			Output: output(batches[i]),
+		}
+
+		if i == len(batches)-1 {
+			update.Status = gogithub.String("completed")
+			update.Conclusion = gogithub.String(conclusion)
+			update.CompletedAt = &gogithub.Timestamp{Time: time.Now()}
+		}
+
+		if _, _, err = c.Client.Checks.UpdateCheckRun(c.ctx, prr.Owner, prr.Repo, checkRun.GetID(), update); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Conclusion returns the check run conclusion of the reviews given the
+// failure and neutral severity thresholds. Only the findings with a comment
+// count, as they are the only ones annotated.
+func Conclusion(reviews Reviews, failure, neutral Severity) string {
+	conclusion := "success"
+
+	for _, review := range reviews.Review {
+		if !review.Commentable() {
+			continue
+		}
+
+		if failure != "" && review.Severity.AtLeast(failure) {
+			return "failure"
+		}
+
+		if neutral != "" && review.Severity.AtLeast(neutral) {
+			conclusion = "neutral"
+		}
+	}
+
+	return conclusion
+}
+
+// AnnotationLevel maps the severity of a finding to a check run annotation
+// level.
+func AnnotationLevel(severity Severity) string {
+	switch {
+	case severity.AtLeast(SeverityHigh):
+		return "failure"
+	case severity.AtLeast(SeverityMedium):
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// newAnnotations returns the annotations of the findings with a comment.
+func newAnnotations(reviews Reviews) (annotations []*gogithub.CheckRunAnnotation) {
+	for _, review := range reviews.Review {
+		if !review.Commentable() {
+			continue
+		}
+
+		start, end := review.Lines()
+
+		message := review.ReviewComment
+		if review.SuggestionComments != "" {
+			message += "\n\nSuggestion:\n" + review.SuggestionComments
+		}
+
+		annotation := &gogithub.CheckRunAnnotation{
+			Path:            gogithub.String(review.File),
+			StartLine:       gogithub.Int(start),
+			EndLine:         gogithub.Int(end),
+			AnnotationLevel: gogithub.String(AnnotationLevel(review.Severity)),
+			Message:         gogithub.String(message),
+		}
+
+		if review.Category != "" {
+			annotation.Title = gogithub.String(review.Category)
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return
+}
+
+// batchAnnotations splits the annotations in batches the API accepts. There
+// is always at least one, possibly empty, batch.
+func batchAnnotations(annotations []*gogithub.CheckRunAnnotation) (batches [][]*gogithub.CheckRunAnnotation) {
+	for len(annotations) > maxAnnotations {
+		batches = append(batches, annotations[:maxAnnotations])
+		annotations = annotations[maxAnnotations:]
+	}
+
+	return append(batches, annotations)
+}
+
+// checkRunTitle counts the findings with a comment by severity.
+func checkRunTitle(reviews Reviews) string {
+	var commentable Reviews
+	for _, review := range reviews.Review {
+		if review.Commentable() {
+			commentable.Review = append(commentable.Review, review)
+		}
+	}
+	reviews = commentable
+
+	if len(reviews.Review) == 0 {
+		return "No findings"
+	}
+
+	var (
+		counts = make(map[Severity]int)
+		parts  []string
+	)
+
+	for _, review := range reviews.Review {
+		severity, err := ParseSeverity(string(review.Severity))
+		if err != nil {
+			severity = SeverityMedium
+		}
+		counts[severity]++
+	}
+
+	for i := len(Severities) - 1; i >= 0; i-- {
+		if n := counts[Severities[i]]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, Severities[i]))
+		}
+	}
+
+	return fmt.Sprintf("%d findings: %s", len(reviews.Review), strings.Join(parts, ", "))
+}
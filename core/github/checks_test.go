@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// newTestClient returns a client that sends its requests to handler.
+func newTestClient(t *testing.T, handler http.Handler) Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := Client{ctx: context.Background(), Client: gogithub.NewClient(nil)}
+	c.Client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return c
+}
+
+func Test_Conclusion(t *testing.T) {
+	var tests = []struct {
+		name       string
+		severities []Severity
+		failure    Severity
+		neutral    Severity
+		expected   string
+	}{
+		{"no findings", nil, SeverityHigh, SeverityMedium, "success"},
+		{"below thresholds", []Severity{SeverityLow, SeverityInfo}, SeverityHigh, SeverityMedium, "success"},
+		{"neutral threshold", []Severity{SeverityLow, SeverityMedium}, SeverityHigh, SeverityMedium, "neutral"},
+		{"failure threshold", []Severity{SeverityMedium, SeverityCritical}, SeverityHigh, SeverityMedium, "failure"},
+		{"thresholds disabled", []Severity{SeverityCritical}, "", "", "success"},
+		{"unknown severity ranks as medium", []Severity{"weird"}, SeverityHigh, SeverityMedium, "neutral"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews Reviews
+			for _, severity := range tt.severities {
+				reviews.Review = append(reviews.Review, Review{Severity: severity, ReviewComment: "check the error"})
+			}
+
+			if conclusion := Conclusion(reviews, tt.failure, tt.neutral); conclusion != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, conclusion)
+			}
+		})
+	}
+}
+
+func Test_ConclusionSkipsFindingsWithoutComment(t *testing.T) {
+	reviews := Reviews{Review: []Review{
+		{File: "a.go", LineNumber: 1, Severity: SeverityCritical},
+		{File: "b.go", LineNumber: 2, Severity: SeverityMedium, ReviewComment: "check the error"},
+		{File: "c.go", LineNumber: 3, Severity: SeverityLow, SuggestionComments: "return err"},
+	}}
+
+	if conclusion := Conclusion(reviews, SeverityHigh, SeverityMedium); conclusion != "neutral" {
+		t.Fatalf("expected neutral, got %s", conclusion)
+	}
+
+	annotations := newAnnotations(reviews)
+	if len(annotations) != 2 || annotations[0].GetPath() != "b.go" || annotations[1].GetPath() != "c.go" {
+		t.Fatalf("expected the annotations of b.go and c.go, got %v", annotations)
+	}
+}
+
+func Test_CreateCheckRun(t *testing.T) {
+	var tests = []struct {
+		name        string
+		findings    int
+		annotations []int
+	}{
+		{"no findings", 0, []int{0}},
+		{"single batch", 50, []int{50}},
+		{"several batches", 120, []int{50, 50, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				annotations []int
+				conclusions []string
+			)
+
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Conclusion string `json:"conclusion"`
+					Output     struct {
+						Annotations []json.RawMessage `json:"annotations"`
+					} `json:"output"`
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				annotations = append(annotations, len(body.Output.Annotations))
+				conclusions = append(conclusions, body.Conclusion)
+				fmt.Fprint(w, `{"id": 1}`)
+			}))
+
+			prr := PullRequestReviewRequest{Owner: "o", Repo: "r", Comment: "summary"}
+			for i := 0; i < tt.findings; i++ {
+				prr.Reviews.Review = append(prr.Reviews.Review, Review{File: "a.go", LineNumber: i + 1, Severity: SeverityLow, ReviewComment: "check the error"})
+			}
+
+			if err := c.CreateCheckRun(prr, CheckRunRequest{HeadSHA: "sha", FailureSeverity: SeverityHigh}); err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(annotations) != fmt.Sprint(tt.annotations) {
+				t.Fatalf("expected batches of %v annotations, got %v", tt.annotations, annotations)
+			}
+
+			for i, conclusion := range conclusions {
+				if last := i == len(conclusions)-1; (conclusion == "success") != last {
+					t.Fatalf("conclusion must only be set on the last request, got %v", conclusions)
+				}
+			}
+		})
+	}
+}
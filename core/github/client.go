@@ -2,24 +2,120 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	gogithub "github.com/google/go-github/v33/github"
+	"github.com/lucasmbaia/power-actions/core/httpcache"
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
-	ctx    context.Context
-	token  string
-	Client *gogithub.Client
+	ctx      context.Context
+	token    string
+	app      *App
+	identity *identity
+	Client   *gogithub.Client
 }
 
-func NewClient(token string) (c Client) {
+// Config holds the credentials of the GitHub clients: either a personal or
+// workflow Token, or a GitHub App, which takes precedence. BaseURL and
+// UploadURL point the clients to a GitHub Enterprise Server; they are left
+// empty for github.com. Responses are cached in Cache, when it is set.
+type Config struct {
+	Token     string
+	App       *App
+	BaseURL   string
+	UploadURL string
+	Cache     httpcache.Store
+}
+
+func NewClient(cfg Config) (c Client, err error) {
 	c.ctx = context.Background()
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(c.ctx, ts)
+	c.token = cfg.Token
+	c.app = cfg.App
+	c.identity = &identity{}
+
+	if !IsEnterprise(cfg.BaseURL) {
+		c.Client = gogithub.NewClient(NewHTTPClient(cfg))
+		return
+	}
 
-	c.Client = gogithub.NewClient(tc)
-	c.token = token
+	c.Client, err = gogithub.NewEnterpriseClient(APIBaseURL(cfg.BaseURL), UploadURL(cfg.BaseURL, cfg.UploadURL), NewHTTPClient(cfg))
 
 	return
 }
+
+// NewHTTPClient returns an HTTP client that authenticates its requests with
+// the credentials of cfg, and revalidates the responses of cfg.Cache. The
+// entries of the cache belong to the token, or to the app, whose installation
+// tokens expire but always reach the same repositories.
+func NewHTTPClient(cfg Config) *http.Client {
+	var (
+		transport http.RoundTripper
+		identity  string
+	)
+
+	if cfg.App != nil {
+		transport = cfg.App.Transport(nil)
+		identity = httpcache.Identity(fmt.Sprintf("app %d %s", cfg.App.id, cfg.App.baseURL))
+	} else {
+		transport = &oauth2.Transport{Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.Token})}
+		identity = httpcache.Identity("token " + cfg.Token)
+	}
+
+	if cfg.Cache != nil {
+		transport = httpcache.NewTransport(transport, cfg.Cache, identity)
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// IsEnterprise reports whether an API base URL belongs to a GitHub
+// Enterprise Server rather than to github.com.
+func IsEnterprise(baseURL string) bool {
+	if baseURL == "" {
+		return false
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return true
+	}
+
+	return !strings.EqualFold(u.Hostname(), "api.github.com") && !strings.EqualFold(u.Hostname(), "github.com")
+}
+
+// APIBaseURL returns the REST API URL of a GitHub Enterprise Server given
+// either its host URL, such as https://github.example.com, or its API URL,
+// such as https://github.example.com/api/v3.
+func APIBaseURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/api/v3") {
+		return baseURL + "/"
+	}
+
+	return baseURL + "/api/v3/"
+}
+
+// UploadURL returns the upload URL of a GitHub Enterprise Server, derived
+// from its API URL unless uploadURL is set.
+func UploadURL(baseURL, uploadURL string) string {
+	if uploadURL != "" {
+		return uploadURL
+	}
+
+	return strings.TrimSuffix(APIBaseURL(baseURL), "/api/v3/") + "/api/uploads/"
+}
+
+// EnterpriseURL returns the URL of a GitHub Enterprise Server from the host
+// of a git remote, or an empty string for github.com.
+func EnterpriseURL(host string) string {
+	if host == "" || strings.EqualFold(host, "github.com") {
+		return ""
+	}
+
+	return "https://" + host
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"testing"
+)
+
+func Test_NewClient(t *testing.T) {
+	var tests = []struct {
+		name      string
+		baseURL   string
+		uploadURL string
+		api       string
+		upload    string
+	}{
+		{"github.com", "", "", "https://api.github.com/", "https://uploads.github.com/"},
+		{"github.com from actions", "https://api.github.com", "", "https://api.github.com/", "https://uploads.github.com/"},
+		{"enterprise host", "https://github.example.com", "", "https://github.example.com/api/v3/", "https://github.example.com/api/uploads/"},
+		{"enterprise api", "https://github.example.com/api/v3", "", "https://github.example.com/api/v3/", "https://github.example.com/api/uploads/"},
+		{"enterprise upload", "https://github.example.com/api/v3/", "https://uploads.example.com/api/uploads/", "https://github.example.com/api/v3/", "https://uploads.example.com/api/uploads/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(Config{BaseURL: tt.baseURL, UploadURL: tt.uploadURL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Client.BaseURL.String() != tt.api || c.Client.UploadURL.String() != tt.upload {
+				t.Fatalf("expected %s and %s, got %s and %s", tt.api, tt.upload, c.Client.BaseURL, c.Client.UploadURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	gogithub "github.com/google/go-github/v33/github"
+	"github.com/lucasmbaia/power-actions/core/command"
+)
+
+// Reactions added to the comments holding a command.
+const (
+	// ReactionAcknowledged acknowledges a command that is being run.
+	ReactionAcknowledged = "eyes"
+	// ReactionDenied answers the commands of users without write access.
+	ReactionDenied = "-1"
+	// ReactionConfused answers the commands that are not understood.
+	ReactionConfused = "confused"
+)
+
+// HasWriteAccess reports whether user can push to the repository, which is
+// required to run commands.
+func (c *Client) HasWriteAccess(owner, repo, user string) (allowed bool, err error) {
+	var level *gogithub.RepositoryPermissionLevel
+
+	if level, _, err = c.Client.Repositories.GetPermissionLevel(c.ctx, owner, repo, user); err != nil {
+		return
+	}
+
+	switch level.GetPermission() {
+	case "admin", "write":
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// ReactToComment adds a reaction to the comment of a comment event, either on
+// the conversation or on a line of the pull request.
+func (c *Client) ReactToComment(event PullRequestEvent, reaction string) (err error) {
+	switch event.Name {
+	case EventIssueComment:
+		_, _, err = c.Client.Reactions.CreateIssueCommentReaction(c.ctx, event.Owner, event.Repo, event.CommentID, reaction)
+	case EventPullRequestReviewComment:
+		_, _, err = c.Client.Reactions.CreatePullRequestCommentReaction(c.ctx, event.Owner, event.Repo, event.CommentID, reaction)
+	default:
+		err = fmt.Errorf("%s events have no comment to react to", event.Name)
+	}
+
+	return
+}
+
+// ID identifies the finding across reviews by its file, last line and
+// category, so it can be ignored with a command.
+func (r Review) ID() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", r.File, r.LineNumber, strings.ToLower(r.Category))))
+
+	return hex.EncodeToString(sum[:4])
+}
+
+// FindingFooter returns the end of the comment of a finding: its ID, with
+// the command that ignores it, and a hidden marker holding the ID, the
+// category of the finding and the model that made it, for ParseFindingMarker.
+func FindingFooter(r Review, model string) string {
+	id := r.ID()
+
+	marker := "<!-- powerpr:finding:" + id
+	if category := strings.ToLower(strings.Join(strings.Fields(r.Category), "-")); category != "" {
+		marker += " category=" + category
+	}
+	if model = strings.Join(strings.Fields(model), "-"); model != "" {
+		marker += " model=" + model
+	}
+
+	return fmt.Sprintf("\n\n<sub>Finding `%s`: reply `%s %s %s` to leave it out of the next reviews.</sub>\n%s -->", id, command.Prefix, command.Ignore, id, marker)
+}
+
+// FindingMarker is the hidden marker of the comment of a finding.
+type FindingMarker struct {
+	ID       string
+	Category string
+	Model    string
+}
+
+var findingMarker = regexp.MustCompile(`<!-- powerpr:finding:([0-9a-f]{8})((?: \w+=\S+)*) -->`)
+
+// ParseFindingMarker returns the marker of the comment of a finding, if the
+// comment has one.
+func ParseFindingMarker(body string) (marker FindingMarker, found bool) {
+	match := findingMarker.FindStringSubmatch(body)
+	if match == nil {
+		return
+	}
+
+	marker.ID = match[1]
+	for _, field := range strings.Fields(match[2]) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "category":
+			marker.Category = value
+		case "model":
+			marker.Model = value
+		}
+	}
+
+	return marker, true
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func Test_HasWriteAccess(t *testing.T) {
+	var tests = []struct {
+		permission string
+		expected   bool
+	}{
+		{"admin", true},
+		{"write", true},
+		{"read", false},
+		{"none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/collaborators/octocat/permission", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"permission": %q}`, tt.permission)
+			})
+
+			c := newTestClient(t, mux)
+
+			allowed, err := c.HasWriteAccess("owner", "repo", "octocat")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if allowed != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, allowed)
+			}
+		})
+	}
+}
+
+func Test_ReactToComment(t *testing.T) {
+	var tests = []struct {
+		event         string
+		path          string
+		errorExpected bool
+	}{
+		{EventIssueComment, "/repos/owner/repo/issues/comments/7/reactions", false},
+		{EventPullRequestReviewComment, "/repos/owner/repo/pulls/comments/7/reactions", false},
+		{EventPullRequest, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			var reaction string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != tt.path {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+
+				var body struct {
+					Content string `json:"content"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Error(err)
+				}
+				reaction = body.Content
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{}`)
+			})
+
+			c := newTestClient(t, mux)
+
+			err := c.ReactToComment(PullRequestEvent{Name: tt.event, Owner: "owner", Repo: "repo", CommentID: 7}, ReactionAcknowledged)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && reaction != ReactionAcknowledged {
+				t.Fatalf("expected the %q reaction, got %q", ReactionAcknowledged, reaction)
+			}
+		})
+	}
+}
+
+func Test_ReviewID(t *testing.T) {
+	finding := Review{File: "a.go", LineNumber: 3, Category: "bug", ReviewComment: "check the error"}
+
+	if id := finding.ID(); len(id) != 8 {
+		t.Fatalf("expected an ID of 8 characters, got %q", id)
+	}
+
+	same := Review{File: "a.go", StartLine: 1, LineNumber: 3, Category: "Bug", ReviewComment: "the error is ignored"}
+	if finding.ID() != same.ID() {
+		t.Fatalf("expected the same ID for a new wording of the finding")
+	}
+
+	other := Review{File: "a.go", LineNumber: 4, Category: "bug"}
+	if finding.ID() == other.ID() {
+		t.Fatalf("expected another ID for another line")
+	}
+}
+
+func Test_ParseFindingMarker(t *testing.T) {
+	finding := Review{File: "a.go", LineNumber: 3, Category: "Error Handling"}
+
+	var tests = []struct {
+		name     string
+		body     string
+		expected FindingMarker
+		found    bool
+	}{
+		{"footer", "check the error" + FindingFooter(finding, "gpt-4o"), FindingMarker{ID: finding.ID(), Category: "error-handling", Model: "gpt-4o"}, true},
+		{"footer without model", "check the error" + FindingFooter(Review{File: "a.go"}, ""), FindingMarker{ID: Review{File: "a.go"}.ID()}, true},
+		{"comment of a user", "check the error", FindingMarker{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marker, found := ParseFindingMarker(tt.body)
+			if found != tt.found || marker != tt.expected {
+				t.Fatalf("expected %+v %v, got %+v %v", tt.expected, tt.found, marker, found)
+			}
+		})
+	}
+}
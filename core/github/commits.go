@@ -0,0 +1,226 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+const (
+	// DefaultFetchWorkers is the number of commits fetched at the same time
+	// when PullRequestReviewRequest.FetchWorkers is not set.
+	DefaultFetchWorkers = 8
+
+	// lowRateRemaining is the number of requests left in the rate limit
+	// window below which requests are spread over the rest of the window.
+	lowRateRemaining = 100
+
+	// maxRateLimitRetries is how many times a request that hit the rate
+	// limit is retried, and maxRateLimitWait the longest it waits to be.
+	maxRateLimitRetries = 3
+	maxRateLimitWait    = 2 * time.Minute
+
+	// secondaryRateLimitWait is the wait after a secondary rate limit error
+	// that does not say how long to wait.
+	secondaryRateLimitWait = time.Minute
+)
+
+// getCommits fetches the details of commits, with up to workers requests at
+// a time, and returns them in the order of commits. The first error cancels
+// the requests left.
+func (c *Client) getCommits(owner, repo string, commits []*gogithub.RepositoryCommit, workers int) (details []*gogithub.RepositoryCommit, err error) {
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		limiter rateLimiter
+		indexes = make(chan int)
+	)
+
+	if workers <= 0 {
+		workers = DefaultFetchWorkers
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	details = make([]*gogithub.RepositoryCommit, len(commits))
+
+	for w := 0; w < workers && w < len(commits); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				detail, fetchErr := c.getCommit(ctx, &limiter, owner, repo, commits[i].GetSHA())
+				if fetchErr != nil {
+					once.Do(func() {
+						err = fmt.Errorf("fetching commit %s: %w", commits[i].GetSHA(), fetchErr)
+						cancel()
+					})
+					continue
+				}
+
+				details[i] = detail
+			}
+		}()
+	}
+
+feed:
+	for i := range commits {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// getCommit fetches a commit with all of its files. GitHub lists the files of
+// a commit 300 at a time, so the pages after the first one are fetched too.
+func (c *Client) getCommit(ctx context.Context, limiter *rateLimiter, owner, repo, sha string) (commit *gogithub.RepositoryCommit, err error) {
+	var (
+		page *gogithub.RepositoryCommit
+		next int
+	)
+
+	if commit, next, err = c.getCommitPage(ctx, limiter, owner, repo, sha, 0); err != nil {
+		return
+	}
+
+	for next != 0 {
+		if page, next, err = c.getCommitPage(ctx, limiter, owner, repo, sha, next); err != nil {
+			return
+		}
+
+		commit.Files = append(commit.Files, page.Files...)
+	}
+
+	return
+}
+
+// getCommitPage fetches a page of the files of a commit, waiting for the rate
+// limit to reset when it is exhausted, and returns the number of the next
+// page, or 0 on the last one.
+func (c *Client) getCommitPage(ctx context.Context, limiter *rateLimiter, owner, repo, sha string, page int) (commit *gogithub.RepositoryCommit, next int, err error) {
+	u := fmt.Sprintf("repos/%v/%v/commits/%v", owner, repo, sha)
+	if page > 0 {
+		u += "?page=" + strconv.Itoa(page)
+	}
+
+	for attempt := 0; ; attempt++ {
+		var (
+			req  *http.Request
+			resp *gogithub.Response
+		)
+
+		if err = limiter.wait(ctx); err != nil {
+			return
+		}
+
+		if req, err = c.Client.NewRequest(http.MethodGet, u, nil); err != nil {
+			return
+		}
+
+		commit = new(gogithub.RepositoryCommit)
+		resp, err = c.Client.Do(ctx, req, commit)
+		if resp != nil {
+			limiter.observe(resp.Rate)
+		}
+
+		if err == nil {
+			return commit, resp.NextPage, nil
+		}
+
+		if attempt == maxRateLimitRetries {
+			return
+		}
+
+		var (
+			rateErr      *gogithub.RateLimitError
+			secondaryErr *gogithub.AbuseRateLimitError
+		)
+
+		switch {
+		case errors.As(err, &rateErr):
+			if time.Until(rateErr.Rate.Reset.Time) > maxRateLimitWait {
+				return
+			}
+			limiter.pauseUntil(rateErr.Rate.Reset.Time)
+		case errors.As(err, &secondaryErr):
+			wait := secondaryErr.GetRetryAfter()
+			if wait == 0 {
+				wait = secondaryRateLimitWait
+			}
+			limiter.pauseUntil(time.Now().Add(wait))
+		default:
+			return
+		}
+	}
+}
+
+// rateLimiter spaces out the requests of the workers once the rate limit of
+// GitHub is close to exhausted, so the requests left last until it resets.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the turn of the next request.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	at := l.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe adjusts the interval between requests to the rate limit headers of
+// a response.
+func (l *rateLimiter) observe(rate gogithub.Rate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rate.Limit == 0 || rate.Remaining >= lowRateRemaining {
+		l.interval = 0
+		return
+	}
+
+	l.interval = time.Until(rate.Reset.Time) / time.Duration(rate.Remaining+1)
+}
+
+// pauseUntil holds every request until t.
+func (l *rateLimiter) pauseUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if t.After(l.next) {
+		l.next = t
+	}
+}
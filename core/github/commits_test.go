@@ -0,0 +1,180 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+func newCommits(n int) (commits []*gogithub.RepositoryCommit) {
+	for i := 0; i < n; i++ {
+		commits = append(commits, &gogithub.RepositoryCommit{SHA: gogithub.String(strconv.Itoa(i))})
+	}
+
+	return
+}
+
+func Test_getCommits(t *testing.T) {
+	const workers = 3
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+
+		// Later commits answer first
+		sha := path.Base(r.URL.Path)
+		n, _ := strconv.Atoi(sha)
+		time.Sleep(time.Duration(10-n) * 2 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		fmt.Fprintf(w, `{"sha":%q,"files":[{"filename":"%s.go"}]}`, sha, sha)
+	})
+	c := newTestClient(t, mux)
+
+	details, err := c.getCommits("owner", "repo", newCommits(10), workers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, detail := range details {
+		if detail.GetSHA() != strconv.Itoa(i) || detail.Files[0].GetFilename() != strconv.Itoa(i)+".go" {
+			t.Fatalf("expected commit %d at %d, got %s", i, i, detail.GetSHA())
+		}
+	}
+
+	if peak > workers || peak < 2 {
+		t.Fatalf("expected up to %d concurrent requests, got %d", workers, peak)
+	}
+}
+
+func Test_getCommitsCancelsOnError(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		if path.Base(r.URL.Path) == "1" {
+			http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	c := newTestClient(t, mux)
+
+	start := time.Now()
+	if _, err := c.getCommits("owner", "repo", newCommits(50), 2); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if requests > 3 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("expected the remaining fetches to be canceled, got %d requests in %s", requests, time.Since(start))
+	}
+}
+
+func Test_getCommitRetriesSecondaryRateLimit(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/0", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/resources-in-the-rest-api#abuse-rate-limits"}`)
+			return
+		}
+		fmt.Fprint(w, `{"sha":"0"}`)
+	})
+	c := newTestClient(t, mux)
+
+	details, err := c.getCommits("owner", "repo", newCommits(1), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 || details[0].GetSHA() != "0" {
+		t.Fatalf("expected a retry, got %d requests", requests)
+	}
+}
+
+func Test_getCommitPagesFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/0", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/owner/repo/commits/0?page=%d>; rel="next"`, r.Host, page+1))
+		}
+		fmt.Fprintf(w, `{"sha":"0","files":[{"filename":"%d.go"}]}`, page)
+	})
+	c := newTestClient(t, mux)
+
+	details, err := c.getCommits("owner", "repo", newCommits(1), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := details[0].Files
+	if len(files) != 3 || files[0].GetFilename() != "1.go" || files[2].GetFilename() != "3.go" {
+		t.Fatalf("expected the files of every page, got %v", files)
+	}
+}
+
+func Test_rateLimiterObserve(t *testing.T) {
+	var tests = []struct {
+		name      string
+		remaining int
+		expected  time.Duration
+	}{
+		{"plenty left", lowRateRemaining, 0},
+		{"close to exhausted", 9, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l rateLimiter
+
+			l.observe(gogithub.Rate{Limit: 5000, Remaining: tt.remaining, Reset: gogithub.Timestamp{Time: time.Now().Add(10 * time.Second)}})
+			if l.interval > tt.expected || l.interval < tt.expected-50*time.Millisecond {
+				t.Fatalf("expected an interval of %s, got %s", tt.expected, l.interval)
+			}
+		})
+	}
+}
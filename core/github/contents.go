@@ -0,0 +1,24 @@
+package github
+
+import (
+	"fmt"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// GetFileContent returns the content of a file of the repository at the given
+// ref, usually the head SHA of the pull request.
+func (c *Client) GetFileContent(owner, repo, path, ref string) (content string, err error) {
+	var file *gogithub.RepositoryContent
+
+	if file, _, _, err = c.Client.Repositories.GetContents(c.ctx, owner, repo, path, &gogithub.RepositoryContentGetOptions{Ref: ref}); err != nil {
+		return
+	}
+
+	if file == nil {
+		err = fmt.Errorf("%s is not a file", path)
+		return
+	}
+
+	return file.GetContent()
+}
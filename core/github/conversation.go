@@ -0,0 +1,56 @@
+package github
+
+import (
+	"sort"
+
+	gogithub "github.com/google/go-github/v33/github"
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// GetConversation returns the conversation of the pull request: its issue
+// comments and the summary bodies of its reviews, in chronological order.
+// Reviews without a summary body are left out, as their inline comments are
+// already part of the files they were made on.
+func (c *Client) GetConversation(prr PullRequestReviewRequest) (posts []content.Post, err error) {
+	var (
+		comments []*gogithub.IssueComment
+		reviews  []*gogithub.PullRequestReview
+	)
+
+	if comments, err = c.listIssueComments(prr.Owner, prr.Repo, prr.PrNumber); err != nil {
+		return
+	}
+
+	if reviews, err = c.listReviews(prr.Owner, prr.Repo, prr.PrNumber); err != nil {
+		return
+	}
+
+	for _, comment := range comments {
+		posts = append(posts, content.Post{
+			Kind:      content.PostComment,
+			Author:    comment.GetUser().GetLogin(),
+			CreatedAt: comment.GetCreatedAt(),
+			Body:      comment.GetBody(),
+		})
+	}
+
+	for _, review := range reviews {
+		if review.GetBody() == "" {
+			continue
+		}
+
+		posts = append(posts, content.Post{
+			Kind:      content.PostReview,
+			Author:    review.GetUser().GetLogin(),
+			CreatedAt: review.GetSubmittedAt(),
+			State:     review.GetState(),
+			Body:      review.GetBody(),
+		})
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	return
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Names of the events that carry a pull request.
+const (
+	EventPullRequest              = "pull_request"
+	EventPullRequestTarget        = "pull_request_target"
+	EventIssueComment             = "issue_comment"
+	EventPullRequestReviewComment = "pull_request_review_comment"
+)
+
+// PullRequestEvent holds the coordinates of the pull request an event is
+// about. HeadSHA and BaseSHA are empty for issue_comment events, whose payload
+// does not carry them, Label is the label added by labeled events, Labels are
+// all the labels of the pull request, and the comment fields are only set for
+// comment events.
+type PullRequestEvent struct {
+	Name        string
+	Action      string
+	Owner       string
+	Repo        string
+	PrNumber    int
+	HeadSHA     string
+	BaseSHA     string
+	Label       string
+	Labels      []string
+	Actor       string
+	CommentID   int64
+	CommentBody string
+}
+
+type label struct {
+	Name string `json:"name"`
+}
+
+type eventPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest *struct {
+		Number int     `json:"number"`
+		Labels []label `json:"labels"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Issue *struct {
+		Number      int              `json:"number"`
+		Labels      []label          `json:"labels"`
+		PullRequest *json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	Label   *label `json:"label"`
+	Comment *struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	} `json:"comment"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// ParseEvent extracts the pull request coordinates from the payload of an
+// event, as delivered to a webhook or found at GITHUB_EVENT_PATH in GitHub
+// Actions. It fails for events that are not about a pull request, including
+// comments on plain issues.
+func ParseEvent(name string, payload []byte) (event PullRequestEvent, err error) {
+	var p eventPayload
+
+	if err = json.Unmarshal(payload, &p); err != nil {
+		return event, fmt.Errorf("invalid %s event payload: %w", name, err)
+	}
+
+	event = PullRequestEvent{
+		Name:   name,
+		Action: p.Action,
+		Owner:  p.Repository.Owner.Login,
+		Repo:   p.Repository.Name,
+		Actor:  p.Sender.Login,
+	}
+
+	if p.Label != nil {
+		event.Label = p.Label.Name
+	}
+
+	if p.Comment != nil {
+		event.CommentID = p.Comment.ID
+		event.CommentBody = p.Comment.Body
+	}
+
+	switch name {
+	case EventPullRequest, EventPullRequestTarget, EventPullRequestReviewComment:
+		if p.PullRequest == nil {
+			return event, fmt.Errorf("%s event has no pull request", name)
+		}
+
+		event.PrNumber = p.PullRequest.Number
+		event.HeadSHA = p.PullRequest.Head.SHA
+		event.BaseSHA = p.PullRequest.Base.SHA
+		event.Labels = labelNames(p.PullRequest.Labels)
+	case EventIssueComment:
+		if p.Issue == nil || p.Issue.PullRequest == nil {
+			return event, fmt.Errorf("%s event is not about a pull request", name)
+		}
+
+		event.PrNumber = p.Issue.Number
+		event.Labels = labelNames(p.Issue.Labels)
+	default:
+		return event, fmt.Errorf("unsupported event %q", name)
+	}
+
+	return
+}
+
+func labelNames(labels []label) (names []string) {
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+
+	return
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseEvent(t *testing.T) {
+	const repository = `"repository": {"name": "repo", "owner": {"login": "owner"}}, "sender": {"login": "octocat"}`
+
+	var tests = []struct {
+		name          string
+		event         string
+		payload       string
+		expected      PullRequestEvent
+		errorExpected bool
+	}{
+		{
+			"labeled pull request",
+			EventPullRequestTarget,
+			`{"action": "labeled", "label": {"name": "ai-reviewer"}, "pull_request": {"number": 15, "labels": [{"name": "bug"}, {"name": "ai-reviewer"}], "head": {"sha": "h"}, "base": {"sha": "b"}}, ` + repository + `}`,
+			PullRequestEvent{Name: EventPullRequestTarget, Action: "labeled", Owner: "owner", Repo: "repo", PrNumber: 15, HeadSHA: "h", BaseSHA: "b", Label: "ai-reviewer", Labels: []string{"bug", "ai-reviewer"}, Actor: "octocat"},
+			false,
+		},
+		{
+			"review comment",
+			EventPullRequestReviewComment,
+			`{"action": "created", "pull_request": {"number": 3, "head": {"sha": "h"}, "base": {"sha": "b"}}, ` + repository + `}`,
+			PullRequestEvent{Name: EventPullRequestReviewComment, Action: "created", Owner: "owner", Repo: "repo", PrNumber: 3, HeadSHA: "h", BaseSHA: "b", Actor: "octocat"},
+			false,
+		},
+		{
+			"comment on a pull request",
+			EventIssueComment,
+			`{"action": "created", "issue": {"number": 7, "pull_request": {"url": "u"}}, "comment": {"id": 9, "body": "/powerpr review"}, ` + repository + `}`,
+			PullRequestEvent{Name: EventIssueComment, Action: "created", Owner: "owner", Repo: "repo", PrNumber: 7, Actor: "octocat", CommentID: 9, CommentBody: "/powerpr review"},
+			false,
+		},
+		{
+			"comment on an issue",
+			EventIssueComment,
+			`{"action": "created", "issue": {"number": 7}, ` + repository + `}`,
+			PullRequestEvent{},
+			true,
+		},
+		{
+			"unsupported event",
+			"push",
+			`{` + repository + `}`,
+			PullRequestEvent{},
+			true,
+		},
+		{
+			"invalid payload",
+			EventPullRequest,
+			`{`,
+			PullRequestEvent{},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseEvent(tt.event, []byte(tt.payload))
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && !reflect.DeepEqual(event, tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, event)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// ActionsBot is the account of the token GitHub Actions gives workflows,
+// which can not read the authenticated user.
+const ActionsBot = "github-actions[bot]"
+
+// identity caches the login of the account the client authenticates as.
+type identity struct {
+	once  sync.Once
+	login string
+	err   error
+}
+
+// Login returns the login of the account the client authenticates as: the
+// bot account of the app, or the owner of the token. Comments are only
+// trusted as the bot's own when their author has this login.
+func (c *Client) Login() (string, error) {
+	if c.identity == nil {
+		return c.fetchLogin()
+	}
+
+	c.identity.once.Do(func() {
+		c.identity.login, c.identity.err = c.fetchLogin()
+	})
+
+	return c.identity.login, c.identity.err
+}
+
+func (c *Client) fetchLogin() (login string, err error) {
+	var (
+		user     *gogithub.User
+		response *gogithub.Response
+	)
+
+	if c.app != nil {
+		var app struct {
+			Slug string `json:"slug"`
+		}
+
+		if err = c.app.appRequest(http.MethodGet, "/app", http.StatusOK, &app); err != nil {
+			return
+		}
+
+		return app.Slug + "[bot]", nil
+	}
+
+	if user, response, err = c.Client.Users.Get(c.ctx, ""); err != nil {
+		var errorResponse *gogithub.ErrorResponse
+
+		// Installation tokens, such as the one of GitHub Actions, are not
+		// users
+		if response != nil && response.StatusCode == http.StatusForbidden && errors.As(err, &errorResponse) {
+			return ActionsBot, nil
+		}
+
+		return
+	}
+
+	return user.GetLogin(), nil
+}
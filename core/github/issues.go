@@ -0,0 +1,116 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v33/github"
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// IssueReference is a reference to an issue found in a pull request
+// description or commit message. Closing is set when the reference follows
+// one of GitHub's closing keywords, such as "Fixes #12".
+type IssueReference struct {
+	Owner   string
+	Repo    string
+	Number  int
+	Closing bool
+}
+
+func (r IssueReference) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+var issueReference = regexp.MustCompile(
+	`(?i)(?:\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s*)?` +
+		`(?:https?://[^/\s]+/([\w.-]+)/([\w.-]+)/issues/|\b([\w.-]+)/([\w.-]+)#|(?:^|[\s(\[])#)(\d+)\b`,
+)
+
+// ParseIssueReferences returns the issues referenced by text, either as
+// "#12", "owner/repo#34" or an issue URL. References without a repository
+// belong to owner/repo. Each issue is returned once, in order of appearance,
+// and is marked as closing if any of its references is.
+func ParseIssueReferences(text, owner, repo string) (refs []IssueReference) {
+	var index = make(map[string]int)
+
+	for _, m := range issueReference.FindAllStringSubmatch(text, -1) {
+		ref := IssueReference{Owner: owner, Repo: repo, Closing: m[1] != ""}
+
+		switch {
+		case m[2] != "":
+			ref.Owner, ref.Repo = m[2], m[3]
+		case m[4] != "":
+			ref.Owner, ref.Repo = m[4], m[5]
+		}
+
+		ref.Number, _ = strconv.Atoi(m[6])
+
+		key := strings.ToLower(ref.String())
+		if i, ok := index[key]; ok {
+			refs[i].Closing = refs[i].Closing || ref.Closing
+			continue
+		}
+
+		index[key] = len(refs)
+		refs = append(refs, ref)
+	}
+
+	return
+}
+
+// GetLinkedIssues fetches the issues referenced by the description and the
+// commit messages of the pull request. References to pull requests and to
+// issues that no longer exist are ignored. The issues fetched before an error
+// are returned along with it.
+func (c *Client) GetLinkedIssues(prr PullRequestReviewRequest, pr content.PullRequest) (issues []content.Issue, err error) {
+	var text = []string{pr.Description}
+
+	for _, commit := range pr.Commits {
+		text = append(text, commit.Message)
+	}
+
+	for _, ref := range ParseIssueReferences(strings.Join(text, "\n"), prr.Owner, prr.Repo) {
+		if ref.Owner == prr.Owner && ref.Repo == prr.Repo && ref.Number == prr.PrNumber {
+			continue
+		}
+
+		var (
+			issue *gogithub.Issue
+			resp  *gogithub.Response
+		)
+
+		if issue, resp, err = c.Client.Issues.Get(c.ctx, ref.Owner, ref.Repo, ref.Number); err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+				err = nil
+				continue
+			}
+
+			err = fmt.Errorf("fetching issue %s: %w", ref, err)
+			return
+		}
+
+		if issue.IsPullRequest() {
+			continue
+		}
+
+		var labels []string
+		for _, label := range issue.Labels {
+			labels = append(labels, label.GetName())
+		}
+
+		issues = append(issues, content.Issue{
+			Reference: ref.String(),
+			Closing:   ref.Closing,
+			State:     issue.GetState(),
+			Title:     issue.GetTitle(),
+			Labels:    labels,
+			Body:      issue.GetBody(),
+		})
+	}
+
+	return
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseIssueReferences(t *testing.T) {
+	var tests = []struct {
+		name     string
+		text     string
+		expected []IssueReference
+	}{
+		{
+			"closing keyword",
+			"Fixes #12",
+			[]IssueReference{{"owner", "repo", 12, true}},
+		},
+		{
+			"closing keyword with colon and other repository",
+			"resolved: other/project#34",
+			[]IssueReference{{"other", "project", 34, true}},
+		},
+		{
+			"plain reference",
+			"Related to #7, see also (#8)",
+			[]IssueReference{{"owner", "repo", 7, false}, {"owner", "repo", 8, false}},
+		},
+		{
+			"issue url",
+			"Closes https://github.com/acme/tool/issues/99",
+			[]IssueReference{{"acme", "tool", 99, true}},
+		},
+		{
+			"duplicates are merged and keep closing",
+			"See #5\n\nclose #5",
+			[]IssueReference{{"owner", "repo", 5, true}},
+		},
+		{
+			"anchors and words are not references",
+			"Jump to page#3 and color #ff0000",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if refs := ParseIssueReferences(tt.text, "owner", "repo"); !reflect.DeepEqual(refs, tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, refs)
+			}
+		})
+	}
+}
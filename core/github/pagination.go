@@ -0,0 +1,135 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+const (
+	// perPage is the size of the pages of the list calls, the largest GitHub
+	// accepts.
+	perPage = 100
+
+	// maxPullRequestCommits is the number of commits the commits endpoint of
+	// a pull request returns at most, whatever the page.
+	maxPullRequestCommits = 250
+)
+
+// listCommits returns every commit of a pull request, oldest first. Past
+// the limit of the commits endpoint, they are read from the comparison of
+// the base and the head of the pull request instead.
+func (c *Client) listCommits(owner, repo string, pullrequest *gogithub.PullRequest) (commits []*gogithub.RepositoryCommit, err error) {
+	if pullrequest.GetCommits() > maxPullRequestCommits {
+		return c.compareCommits(owner, repo, pullrequest.GetBase().GetSHA(), pullrequest.GetHead().GetSHA())
+	}
+
+	opts := &gogithub.ListOptions{PerPage: perPage}
+	for {
+		var (
+			page []*gogithub.RepositoryCommit
+			resp *gogithub.Response
+		)
+
+		if page, resp, err = c.Client.PullRequests.ListCommits(c.ctx, owner, repo, pullrequest.GetNumber(), opts); err != nil {
+			return
+		}
+
+		commits = append(commits, page...)
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// compareCommits returns the commits between base and head, oldest first,
+// from every page of the comparison, which go-github does not paginate.
+func (c *Client) compareCommits(owner, repo, base, head string) (commits []*gogithub.RepositoryCommit, err error) {
+	for page := 1; page != 0; {
+		var (
+			req        *http.Request
+			resp       *gogithub.Response
+			comparison gogithub.CommitsComparison
+		)
+
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=%d&page=%d", owner, repo, base, head, perPage, page)
+		if req, err = c.Client.NewRequest("GET", u, nil); err != nil {
+			return
+		}
+
+		if resp, err = c.Client.Do(c.ctx, req, &comparison); err != nil {
+			return
+		}
+
+		commits = append(commits, comparison.Commits...)
+		page = resp.NextPage
+	}
+
+	return
+}
+
+// listReviewComments returns every review comment of a pull request.
+func (c *Client) listReviewComments(owner, repo string, number int) (comments []*gogithub.PullRequestComment, err error) {
+	opts := &gogithub.PullRequestListCommentsOptions{ListOptions: gogithub.ListOptions{PerPage: perPage}}
+	for {
+		var (
+			page []*gogithub.PullRequestComment
+			resp *gogithub.Response
+		)
+
+		if page, resp, err = c.Client.PullRequests.ListComments(c.ctx, owner, repo, number, opts); err != nil {
+			return
+		}
+
+		comments = append(comments, page...)
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listReviews returns every review of a pull request.
+func (c *Client) listReviews(owner, repo string, number int) (reviews []*gogithub.PullRequestReview, err error) {
+	opts := &gogithub.ListOptions{PerPage: perPage}
+	for {
+		var (
+			page []*gogithub.PullRequestReview
+			resp *gogithub.Response
+		)
+
+		if page, resp, err = c.Client.PullRequests.ListReviews(c.ctx, owner, repo, number, opts); err != nil {
+			return
+		}
+
+		reviews = append(reviews, page...)
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listIssueComments returns every comment of the conversation of a pull
+// request.
+func (c *Client) listIssueComments(owner, repo string, number int) (comments []*gogithub.IssueComment, err error) {
+	opts := &gogithub.IssueListCommentsOptions{ListOptions: gogithub.ListOptions{PerPage: perPage}}
+	for {
+		var (
+			page []*gogithub.IssueComment
+			resp *gogithub.Response
+		)
+
+		if page, resp, err = c.Client.Issues.ListComments(c.ctx, owner, repo, number, opts); err != nil {
+			return
+		}
+
+		comments = append(comments, page...)
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
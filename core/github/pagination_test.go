@@ -0,0 +1,129 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// paginate serves items on pages of two, with the Link header GitHub sends to
+// point to the next page, and counts the requests made.
+func paginate(t *testing.T, items []string, requests *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		if r.URL.Query().Get("per_page") != strconv.Itoa(perPage) {
+			t.Errorf("expected per_page=%d, got %q", perPage, r.URL.RawQuery)
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		start, end := (page-1)*2, page*2
+		if end >= len(items) {
+			end = len(items)
+		} else {
+			next := *r.URL
+			query := next.Query()
+			query.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = query.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+		}
+
+		var body string
+		for i, item := range items[start:end] {
+			if i > 0 {
+				body += ","
+			}
+			body += item
+		}
+
+		if r.URL.Path == "/repos/owner/repo/compare/base...head" {
+			fmt.Fprintf(w, `{"commits":[%s]}`, body)
+			return
+		}
+		fmt.Fprintf(w, "[%s]", body)
+	}
+}
+
+func Test_listCommits(t *testing.T) {
+	commits := []string{`{"sha":"1"}`, `{"sha":"2"}`, `{"sha":"3"}`, `{"sha":"4"}`, `{"sha":"5"}`}
+
+	var tests = []struct {
+		name     string
+		count    int
+		endpoint string
+	}{
+		{"commits endpoint", 5, "/repos/owner/repo/pulls/1/commits"},
+		{"compare fallback past the limit", maxPullRequestCommits + 1, "/repos/owner/repo/compare/base...head"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc(tt.endpoint, paginate(t, commits, &requests))
+			c := newTestClient(t, mux)
+
+			pullrequest := &gogithub.PullRequest{
+				Number:  gogithub.Int(1),
+				Commits: gogithub.Int(tt.count),
+				Base:    &gogithub.PullRequestBranch{SHA: gogithub.String("base")},
+				Head:    &gogithub.PullRequestBranch{SHA: gogithub.String("head")},
+			}
+
+			got, err := c.listCommits("owner", "repo", pullrequest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if requests != 3 || len(got) != len(commits) {
+				t.Fatalf("expected %d commits in 3 requests, got %d in %d", len(commits), len(got), requests)
+			}
+
+			for i, commit := range got {
+				if commit.GetSHA() != strconv.Itoa(i+1) {
+					t.Fatalf("expected commit %d at %d, got %s", i+1, i, commit.GetSHA())
+				}
+			}
+		})
+	}
+}
+
+func Test_listComments(t *testing.T) {
+	var (
+		requests int
+		comments = []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/issues/1/comments", paginate(t, comments, &requests))
+	mux.HandleFunc("/repos/owner/repo/pulls/1/comments", paginate(t, comments, &requests))
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", paginate(t, comments, &requests))
+	c := newTestClient(t, mux)
+
+	issueComments, err := c.listIssueComments("owner", "repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviewComments, err := c.listReviewComments("owner", "repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews, err := c.listReviews("owner", "repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issueComments) != 3 || len(reviewComments) != 3 || len(reviews) != 3 || requests != 6 {
+		t.Fatalf("expected 3 of each in 6 requests, got %d, %d and %d in %d", len(issueComments), len(reviewComments), len(reviews), requests)
+	}
+}
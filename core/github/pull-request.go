@@ -1,21 +1,40 @@
 package github
 
 import (
-	"fmt"
-
 	gogithub "github.com/google/go-github/v33/github"
-	"github.com/lucasmbaia/power-actions/core/prompt"
+	"github.com/lucasmbaia/power-actions/core/content"
 )
 
 type Reviews struct {
 	Review []Review `json:"reviews"`
 }
 
+// Review is a finding of the model. LineNumber is the last line of the
+// finding in the new version of the file, and StartLine its first line when
+// the finding spans more than one line.
 type Review struct {
-	File               string `json:"file"`
-	LineNumber         int    `json:"lineNumber"`
-	ReviewComment      string `json:"reviewComment"`
-	SuggestionComments string `json:"suggestionComments"`
+	File               string   `json:"file"`
+	StartLine          int      `json:"startLine,omitempty"`
+	LineNumber         int      `json:"lineNumber"`
+	Severity           Severity `json:"severity"`
+	Category           string   `json:"category"`
+	ReviewComment      string   `json:"reviewComment"`
+	SuggestionComments string   `json:"suggestionComments"`
+}
+
+// Lines returns the first and the last line of the finding.
+func (r Review) Lines() (start, end int) {
+	if r.StartLine > 0 && r.StartLine < r.LineNumber {
+		return r.StartLine, r.LineNumber
+	}
+
+	return r.LineNumber, r.LineNumber
+}
+
+// Commentable reports whether the finding has a comment or a suggestion, the
+// findings without either are not posted.
+func (r Review) Commentable() bool {
+	return r.ReviewComment != "" || r.SuggestionComments != ""
 }
 
 type PullRequestReviewRequest struct {
@@ -25,6 +44,13 @@ type PullRequestReviewRequest struct {
 	PrNumber        int
 	Reviews         Reviews
 	MaxChangedLines int
+
+	// FetchWorkers is the number of commits fetched at the same time,
+	// DefaultFetchWorkers when it is not set.
+	FetchWorkers int
+
+	// Model is the model that made the findings, recorded in their comments.
+	Model string
 }
 
 func (c *Client) PullRequestReview(prr PullRequestReviewRequest) (err error) {
@@ -39,11 +65,20 @@ func (c *Client) PullRequestReview(prr PullRequestReviewRequest) (err error) {
 			comment += "\n```suggestion\n" + value.SuggestionComments + "\n```"
 		}
 		if comment != "" {
-			comments = append(comments, &gogithub.DraftReviewComment{
-				Path:     gogithub.String(value.File),
-				Position: gogithub.Int(value.LineNumber),
-				Body:     gogithub.String(comment),
-			})
+			comment += FindingFooter(value, prr.Model)
+			draft := &gogithub.DraftReviewComment{
+				Path: gogithub.String(value.File),
+				Side: gogithub.String("RIGHT"),
+				Line: gogithub.Int(value.LineNumber),
+				Body: gogithub.String(comment),
+			}
+
+			if start, end := value.Lines(); start != end {
+				draft.StartSide = gogithub.String("RIGHT")
+				draft.StartLine = gogithub.Int(start)
+			}
+
+			comments = append(comments, draft)
 		}
 	}
 
@@ -58,10 +93,11 @@ func (c *Client) PullRequestReview(prr PullRequestReviewRequest) (err error) {
 	return
 }
 
-func (c *Client) GetPullRequestChanges(prr PullRequestReviewRequest) (contentPullRequest string, err error) {
+func (c *Client) GetPullRequestChanges(prr PullRequestReviewRequest) (pr content.PullRequest, err error) {
 	var (
 		pullrequest *gogithub.PullRequest
 		commits     []*gogithub.RepositoryCommit
+		details     []*gogithub.RepositoryCommit
 		comments    []*gogithub.PullRequestComment
 	)
 
@@ -69,78 +105,127 @@ func (c *Client) GetPullRequestChanges(prr PullRequestReviewRequest) (contentPul
 		return
 	}
 
-	if commits, _, err = c.Client.PullRequests.ListCommits(c.ctx, prr.Owner, prr.Repo, prr.PrNumber, nil); err != nil {
+	if commits, err = c.listCommits(prr.Owner, prr.Repo, pullrequest); err != nil {
 		return
 	}
 
-	if comments, _, err = c.Client.PullRequests.ListComments(c.ctx, prr.Owner, prr.Repo, prr.PrNumber, nil); err != nil {
+	if comments, err = c.listReviewComments(prr.Owner, prr.Repo, prr.PrNumber); err != nil {
 		return
 	}
 
-	contentPullRequest += fmt.Sprintf(
-		"Pull request title: %s\nPull request description:\n%s\n%s\n%s\n",
-		pullrequest.GetTitle(),
-		prompt.PR_BODY_START,
-		pullrequest.GetBody(),
-		prompt.PR_BODY_END,
-	)
+	pr = content.PullRequest{
+		Title:       pullrequest.GetTitle(),
+		Description: pullrequest.GetBody(),
+		HeadSHA:     pullrequest.GetHead().GetSHA(),
+	}
+
+	for _, label := range pullrequest.Labels {
+		pr.Labels = append(pr.Labels, label.GetName())
+	}
+
+	if details, err = c.getCommits(prr.Owner, prr.Repo, commits, prr.FetchWorkers); err != nil {
+		return
+	}
+
+	for i, commit := range commits {
+		pr.Commits = append(pr.Commits, newCommit(commit, details[i].Files, comments, prr.MaxChangedLines))
+	}
+
+	return
+}
+
+// newCommit converts a commit and its files into the content model, attaching
+// to each file the review comments made on it in that commit.
+func newCommit(commit *gogithub.RepositoryCommit, files []*gogithub.CommitFile, comments []*gogithub.PullRequestComment, maxChangedLines int) (cc content.Commit) {
+	cc = content.Commit{
+		SHA:     commit.GetSHA(),
+		Message: commit.GetCommit().GetMessage(),
+	}
 
-	for _, commit := range commits {
-		var commitInfos *gogithub.RepositoryCommit
-		if commitInfos, _, err = c.Client.Repositories.GetCommit(c.ctx, prr.Owner, prr.Repo, commit.GetSHA()); err != nil {
-			return
+	for _, file := range files {
+		if file.GetChanges() > maxChangedLines {
+			continue
 		}
 
-		contentPullRequest += fmt.Sprintf("%s\nCommitID: %s\n", prompt.BEGIN_CONTENT, commit.GetSHA())
+		cf := content.File{
+			PreviousFilename: file.GetPreviousFilename(),
+			Filename:         file.GetFilename(),
+			Status:           file.GetStatus(),
+			Additions:        file.GetAdditions(),
+			Deletions:        file.GetDeletions(),
+			Changes:          file.GetChanges(),
+			Hunks:            content.ParsePatch(file.GetPatch()),
+		}
 
-		for _, file := range commitInfos.Files {
-			if *file.Changes > prr.MaxChangedLines {
+		for _, comment := range comments {
+			if comment.GetPath() != file.GetFilename() || comment.GetCommitID() != commit.GetSHA() {
 				continue
 			}
 
-			contentPullRequest += fmt.Sprintf(
-				"\nPrevious filename: %s\nFilename: %s\nAdditions: %d\nDeletions: %d\nChanges: %d\nStatus: %s\nPatch:\n%s\n%s\n%s\n",
-				file.GetPreviousFilename(),
-				file.GetFilename(),
-				file.GetAdditions(),
-				file.GetDeletions(),
-				file.GetChanges(),
-				file.GetStatus(),
-				prompt.PATCH_START,
-				file.GetPatch(),
-				prompt.PATCH_END,
-			)
-			for _, comment := range comments {
-				var prComments string
-
-				if comment.GetPath() == file.GetFilename() && comment.GetCommitID() == commit.GetSHA() {
-					var position int
-					if comment.Position != nil {
-						position = comment.GetPosition()
-					}
-
-					if comment.OriginalPosition != nil {
-						position = comment.GetOriginalPosition()
-					}
-
-					prComments += fmt.Sprintf(
-						"\tComment:\n\t\tLine: %d\n\t\tUser: %s\n\t\tBody:\n%s\n%s\n%s\n",
-						position,
-						comment.GetUser().GetLogin(),
-						prompt.COMMENT_BODY_START,
-						comment.GetBody(),
-						prompt.COMMENT_BODY_END,
-					)
-				}
-
-				if prComments != "" {
-					contentPullRequest += fmt.Sprintf("Comments:\n%s", prComments)
-				}
-			}
+			cf.Comments = append(cf.Comments, content.Comment{
+				Line: commentLine(comment, cf.Hunks),
+				User: comment.GetUser().GetLogin(),
+				Body: comment.GetBody(),
+			})
 		}
 
-		contentPullRequest += prompt.END_CONTENT + "\n"
+		cc.Files = append(cc.Files, cf)
 	}
 
 	return
 }
+
+// commentLine returns the line of the new version of the file a review
+// comment is on, or 0 when it is on a removed line. Outdated comments only
+// have the line they were made on, and the comments made before GitHub
+// reported lines only have their position in the diff, which is converted
+// with the hunks of the file.
+func commentLine(comment *gogithub.PullRequestComment, hunks []content.Hunk) int {
+	if comment.GetSide() == "LEFT" {
+		return 0
+	}
+
+	if comment.Line != nil {
+		return comment.GetLine()
+	}
+
+	if comment.OriginalLine != nil {
+		return comment.GetOriginalLine()
+	}
+
+	position := comment.GetOriginalPosition()
+	if comment.Position != nil {
+		position = comment.GetPosition()
+	}
+
+	line, _ := content.PositionLine(hunks, position)
+
+	return line
+}
+
+// NewPullRequest describes a pull request to open from the Head branch into
+// the Base branch.
+type NewPullRequest struct {
+	Owner       string
+	Repo        string
+	Title       string
+	Description string
+	Base        string
+	Head        string
+}
+
+// CreatePullRequest opens a pull request and returns its URL.
+func (c *Client) CreatePullRequest(npr NewPullRequest) (url string, err error) {
+	var pullrequest *gogithub.PullRequest
+
+	if pullrequest, _, err = c.Client.PullRequests.Create(c.ctx, npr.Owner, npr.Repo, &gogithub.NewPullRequest{
+		Title: gogithub.String(npr.Title),
+		Body:  gogithub.String(npr.Description),
+		Base:  gogithub.String(npr.Base),
+		Head:  gogithub.String(npr.Head),
+	}); err != nil {
+		return
+	}
+
+	return pullrequest.GetHTMLURL(), nil
+}
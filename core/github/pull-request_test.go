@@ -4,18 +4,23 @@ import (
 	"fmt"
 	"os"
 	"testing"
+
+	gogithub "github.com/google/go-github/v33/github"
+	"github.com/lucasmbaia/power-actions/core/content"
 )
 
 func Test_GetPullRequestChanges(t *testing.T) {
 	var (
-		c       Client
-		err     error
-		content string
+		c   Client
+		err error
+		pr  content.PullRequest
 	)
 
-	c = NewClient(os.Getenv("GITHUB_TOKEN"))
+	if c, err = NewClient(Config{Token: os.Getenv("GITHUB_TOKEN")}); err != nil {
+		t.Fatal(err)
+	}
 
-	if content, err = c.GetPullRequestChanges(PullRequestReviewRequest{
+	if pr, err = c.GetPullRequestChanges(PullRequestReviewRequest{
 		Owner:           os.Getenv("GITHUB_OWNER"),
 		Repo:            os.Getenv("GITHUB_REPO"),
 		PrNumber:        15,
@@ -24,5 +29,49 @@ func Test_GetPullRequestChanges(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	fmt.Println(content)
+	fmt.Println(content.Render(pr))
+}
+
+func Test_commentLine(t *testing.T) {
+	hunks := content.ParsePatch("@@ -1,2 +1,3 @@\n a\n-b\n+c\n+d")
+
+	var tests = []struct {
+		name     string
+		comment  gogithub.PullRequestComment
+		expected int
+	}{
+		{"line", gogithub.PullRequestComment{Line: gogithub.Int(7), Position: gogithub.Int(3)}, 7},
+		{"outdated", gogithub.PullRequestComment{OriginalLine: gogithub.Int(5), OriginalPosition: gogithub.Int(3)}, 5},
+		{"position only", gogithub.PullRequestComment{Position: gogithub.Int(4)}, 3},
+		{"original position only", gogithub.PullRequestComment{OriginalPosition: gogithub.Int(3)}, 2},
+		{"removed line", gogithub.PullRequestComment{Side: gogithub.String("LEFT"), Line: gogithub.Int(2)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if line := commentLine(&tt.comment, hunks); line != tt.expected {
+				t.Fatalf("expected line %d, got %d", tt.expected, line)
+			}
+		})
+	}
+}
+
+func Test_Commentable(t *testing.T) {
+	var tests = []struct {
+		name     string
+		review   Review
+		expected bool
+	}{
+		{"comment", Review{ReviewComment: "check the error"}, true},
+		{"suggestion", Review{SuggestionComments: "return nil"}, true},
+		{"neither", Review{File: "a.go", LineNumber: 3, Severity: SeverityCritical}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if commentable := tt.review.Commentable(); commentable != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, commentable)
+			}
+		})
+	}
 }
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a review finding is.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Severities lists the known severities from the least to the most serious.
+var Severities = []Severity{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ParseSeverity parses a severity name, ignoring its case.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range Severities {
+		if strings.EqualFold(s, string(severity)) {
+			return severity, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid severity %q, expected one of %v", s, Severities)
+}
+
+// AtLeast reports whether s is as serious as other or more. Unknown
+// severities, such as one the model made up, rank as SeverityMedium.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+func (s Severity) rank() int {
+	for i, severity := range Severities {
+		if strings.EqualFold(string(s), string(severity)) {
+			return i
+		}
+	}
+
+	return SeverityMedium.rank()
+}
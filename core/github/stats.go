@@ -0,0 +1,359 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// FindingComment is the comment of a finding posted by powerpr on a pull
+// request, with the feedback it received.
+type FindingComment struct {
+	PrNumber  int       `json:"prNumber"`
+	CommentID int64     `json:"commentId"`
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Category and Model come from the marker of the comment, and are empty
+	// for the comments posted before it recorded them.
+	Category string `json:"category,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	ThumbsUp   int  `json:"thumbsUp"`
+	ThumbsDown int  `json:"thumbsDown"`
+	Replies    int  `json:"replies"`
+	Resolved   bool `json:"resolved"`
+
+	// Suggested is set when the comment holds a suggestion, and Applied when
+	// a later commit of the pull request adds the suggested code.
+	Suggested bool `json:"suggested"`
+	Applied   bool `json:"applied"`
+}
+
+// FindingCommentsRequest selects the comments of findings of the pull
+// requests of a repository updated since Since. Comments are recognized by
+// the marker of their finding. When Bot is set, only its comments are, and
+// its comments posted before the marker existed too.
+type FindingCommentsRequest struct {
+	Owner        string
+	Repo         string
+	Since        time.Time
+	Bot          string
+	FetchWorkers int
+}
+
+// GetFindingComments returns the comments of findings posted since
+// req.Since, with their reactions, replies, whether their thread is resolved
+// and whether their suggestion was applied.
+func (c *Client) GetFindingComments(req FindingCommentsRequest) (findings []FindingComment, err error) {
+	var pullRequests []*gogithub.PullRequest
+
+	if pullRequests, err = c.listPullRequestsSince(req.Owner, req.Repo, req.Since); err != nil {
+		return
+	}
+
+	for _, pullRequest := range pullRequests {
+		var prFindings []FindingComment
+
+		if prFindings, err = c.getPullRequestFindings(req, pullRequest); err != nil {
+			return nil, fmt.Errorf("pull request #%d: %w", pullRequest.GetNumber(), err)
+		}
+
+		findings = append(findings, prFindings...)
+	}
+
+	return
+}
+
+func (c *Client) getPullRequestFindings(req FindingCommentsRequest, pullRequest *gogithub.PullRequest) (findings []FindingComment, err error) {
+	var (
+		comments    []*gogithub.PullRequestComment
+		resolved    map[int64]bool
+		suggestions = make(map[int]string)
+		replies     = make(map[int64]int)
+	)
+
+	if comments, err = c.listReviewComments(req.Owner, req.Repo, pullRequest.GetNumber()); err != nil {
+		return
+	}
+
+	for _, comment := range comments {
+		if comment.InReplyTo != nil {
+			replies[comment.GetInReplyTo()]++
+		}
+	}
+
+	for _, comment := range comments {
+		marker, found := ParseFindingMarker(comment.GetBody())
+
+		// Anyone can quote a marker: when the bot is known, only its own
+		// comments are findings
+		if req.Bot != "" {
+			found = strings.EqualFold(comment.GetUser().GetLogin(), req.Bot)
+		}
+
+		if !found {
+			continue
+		}
+
+		if comment.InReplyTo != nil || comment.GetCreatedAt().Before(req.Since) {
+			continue
+		}
+
+		finding := FindingComment{
+			PrNumber:   pullRequest.GetNumber(),
+			CommentID:  comment.GetID(),
+			File:       comment.GetPath(),
+			CreatedAt:  comment.GetCreatedAt(),
+			Category:   marker.Category,
+			Model:      marker.Model,
+			ThumbsUp:   comment.GetReactions().GetPlusOne(),
+			ThumbsDown: comment.GetReactions().GetMinusOne(),
+			Replies:    replies[comment.GetID()],
+		}
+
+		if suggestion, found := Suggestion(comment.GetBody()); found {
+			finding.Suggested = true
+			suggestions[len(findings)] = suggestion
+		}
+
+		findings = append(findings, finding)
+	}
+
+	if len(findings) == 0 {
+		return
+	}
+
+	if resolved, err = c.resolvedThreads(req.Owner, req.Repo, pullRequest.GetNumber()); err != nil {
+		return
+	}
+
+	for i := range findings {
+		findings[i].Resolved = resolved[findings[i].CommentID]
+	}
+
+	if len(suggestions) > 0 {
+		err = c.findAppliedSuggestions(req, pullRequest, findings, suggestions)
+	}
+
+	return
+}
+
+// findAppliedSuggestions flags the findings whose suggestion, indexed by the
+// position of the finding, is added by a commit made after the comment.
+func (c *Client) findAppliedSuggestions(req FindingCommentsRequest, pullRequest *gogithub.PullRequest, findings []FindingComment, suggestions map[int]string) (err error) {
+	var (
+		commits []*gogithub.RepositoryCommit
+		later   []*gogithub.RepositoryCommit
+		details []*gogithub.RepositoryCommit
+		first   time.Time
+	)
+
+	for i := range suggestions {
+		if first.IsZero() || findings[i].CreatedAt.Before(first) {
+			first = findings[i].CreatedAt
+		}
+	}
+
+	if commits, err = c.listCommits(req.Owner, req.Repo, pullRequest); err != nil {
+		return
+	}
+
+	for _, commit := range commits {
+		if commit.GetCommit().GetCommitter().GetDate().After(first) {
+			later = append(later, commit)
+		}
+	}
+
+	if details, err = c.getCommits(req.Owner, req.Repo, later, req.FetchWorkers); err != nil {
+		return
+	}
+
+	for i, suggestion := range suggestions {
+		for _, commit := range details {
+			if !commit.GetCommit().GetCommitter().GetDate().After(findings[i].CreatedAt) {
+				continue
+			}
+
+			for _, file := range commit.Files {
+				if file.GetFilename() == findings[i].File && SuggestionApplied(suggestion, file.GetPatch()) {
+					findings[i].Applied = true
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// listPullRequestsSince returns the pull requests of a repository updated
+// since the given time, in any state.
+func (c *Client) listPullRequestsSince(owner, repo string, since time.Time) (pullRequests []*gogithub.PullRequest, err error) {
+	opts := &gogithub.PullRequestListOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: gogithub.ListOptions{PerPage: perPage},
+	}
+
+	for {
+		var (
+			page []*gogithub.PullRequest
+			resp *gogithub.Response
+		)
+
+		if page, resp, err = c.Client.PullRequests.List(c.ctx, owner, repo, opts); err != nil {
+			return
+		}
+
+		for _, pullRequest := range page {
+			if pullRequest.GetUpdatedAt().Before(since) {
+				return
+			}
+			pullRequests = append(pullRequests, pullRequest)
+		}
+
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes { isResolved comments(first: 1) { nodes { databaseId } } }
+      }
+    }
+  }
+}`
+
+type reviewThreadsResponse struct {
+	Data struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						IsResolved bool `json:"isResolved"`
+						Comments   struct {
+							Nodes []struct {
+								DatabaseID int64 `json:"databaseId"`
+							} `json:"nodes"`
+						} `json:"comments"`
+					} `json:"nodes"`
+				} `json:"reviewThreads"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// resolvedThreads returns whether the review threads of a pull request are
+// resolved, by the ID of their first comment. The REST API does not tell,
+// so they are read from the GraphQL API.
+func (c *Client) resolvedThreads(owner, repo string, number int) (resolved map[int64]bool, err error) {
+	var cursor *string
+
+	resolved = make(map[int64]bool)
+
+	for {
+		var (
+			req      *http.Request
+			response reviewThreadsResponse
+		)
+
+		// The GraphQL endpoint is /graphql on github.com, and /api/graphql
+		// next to /api/v3 on GitHub Enterprise Server.
+		if req, err = c.Client.NewRequest("POST", "../graphql", map[string]interface{}{
+			"query": reviewThreadsQuery,
+			"variables": map[string]interface{}{
+				"owner":  owner,
+				"repo":   repo,
+				"number": number,
+				"cursor": cursor,
+			},
+		}); err != nil {
+			return nil, err
+		}
+
+		if _, err = c.Client.Do(c.ctx, req, &response); err != nil {
+			return nil, err
+		}
+
+		if len(response.Errors) > 0 {
+			return nil, fmt.Errorf("listing the review threads: %s", response.Errors[0].Message)
+		}
+
+		threads := response.Data.Repository.PullRequest.ReviewThreads
+		for _, thread := range threads.Nodes {
+			if len(thread.Comments.Nodes) > 0 {
+				resolved[thread.Comments.Nodes[0].DatabaseID] = thread.IsResolved
+			}
+		}
+
+		if !threads.PageInfo.HasNextPage {
+			return resolved, nil
+		}
+		cursor = &threads.PageInfo.EndCursor
+	}
+}
+
+var suggestionBlock = regexp.MustCompile("(?s)```suggestion[^\n]*\n(.*?)\n?```")
+
+// Suggestion returns the code of the suggestion of a comment, if it has one.
+func Suggestion(body string) (code string, found bool) {
+	match := suggestionBlock.FindStringSubmatch(body)
+	if match == nil {
+		return
+	}
+
+	return match[1], true
+}
+
+// SuggestionApplied reports whether a patch adds every non-blank line of a
+// suggestion, in order, ignoring the indentation. Suggestions that only
+// remove lines are never reported as applied.
+func SuggestionApplied(suggestion, patch string) bool {
+	var (
+		expected []string
+		next     int
+	)
+
+	for _, line := range strings.Split(suggestion, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			expected = append(expected, line)
+		}
+	}
+
+	if len(expected) == 0 {
+		return false
+	}
+
+	for _, line := range strings.Split(patch, "\n") {
+		added, found := strings.CutPrefix(line, "+")
+		if !found || strings.HasPrefix(line, "+++") {
+			continue
+		}
+
+		if strings.TrimSpace(added) == expected[next] {
+			if next++; next == len(expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_SuggestionApplied(t *testing.T) {
+	var tests = []struct {
+		name       string
+		suggestion string
+		patch      string
+		expected   bool
+	}{
+		{"added with another indentation", "if err != nil {\n\treturn err\n}", "@@ -1,2 +1,4 @@\n x := f()\n+  if err != nil {\n+    return err\n+  }", true},
+		{"partially added", "if err != nil {\n\treturn err\n}", "@@ -1 +1,2 @@\n+if err != nil {", false},
+		{"removed lines", "return nil", "@@ -1 +0,0 @@\n-return nil", false},
+		{"empty suggestion", "\n", "@@ -1 +1 @@\n+x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if applied := SuggestionApplied(tt.suggestion, tt.patch); applied != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, applied)
+			}
+		})
+	}
+}
+
+func Test_FindingComments(t *testing.T) {
+	var (
+		since    = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		finding  = Review{File: "a.go", LineNumber: 3, Category: "bug"}
+		suggests = "check the error\n```suggestion\nreturn err\n```" + FindingFooter(finding, "gpt-4o")
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"number": 2, "updated_at": "2024-06-10T00:00:00Z", "base": {"sha": "b"}, "head": {"sha": "h"}, "commits": 2},
+			{"number": 1, "updated_at": "2024-05-10T00:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/2/comments", func(w http.ResponseWriter, r *http.Request) {
+		comments := []map[string]interface{}{
+			{"id": 10, "path": "a.go", "body": suggests, "created_at": "2024-06-02T00:00:00Z", "user": map[string]string{"login": "powerpr[bot]"}, "reactions": map[string]int{"+1": 2}},
+			{"id": 11, "path": "a.go", "body": "thanks", "created_at": "2024-06-02T01:00:00Z", "in_reply_to_id": 10, "user": map[string]string{"login": "octocat"}},
+			{"id": 12, "path": "b.md", "body": "old comment without marker", "created_at": "2024-06-02T00:00:00Z", "user": map[string]string{"login": "powerpr[bot]"}, "reactions": map[string]int{"-1": 1}},
+			{"id": 13, "path": "b.md", "body": "comment of a user", "created_at": "2024-06-02T00:00:00Z", "user": map[string]string{"login": "octocat"}},
+			{"id": 14, "path": "a.go", "body": "> " + suggests, "created_at": "2024-06-02T02:00:00Z", "user": map[string]string{"login": "mallory"}},
+		}
+		json.NewEncoder(w).Encode(comments)
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"pageInfo": {"hasNextPage": false},
+			"nodes": [{"isResolved": true, "comments": {"nodes": [{"databaseId": 10}]}}, {"isResolved": false, "comments": {"nodes": [{"databaseId": 12}]}}]
+		}}}}}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/2/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"sha": "c1", "commit": {"committer": {"date": "2024-06-01T12:00:00Z"}}},
+			{"sha": "c2", "commit": {"committer": {"date": "2024-06-03T00:00:00Z"}}}
+		]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/c2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "c2", "commit": {"committer": {"date": "2024-06-03T00:00:00Z"}}, "files": [{"filename": "a.go", "patch": "@@ -3 +3 @@\n-return nil\n+return err"}]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/c1", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("the commits made before the comments should not be fetched")
+	})
+
+	c := newTestClient(t, mux)
+
+	findings, err := c.GetFindingComments(FindingCommentsRequest{Owner: "owner", Repo: "repo", Since: since, Bot: "powerpr[bot]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+
+	expected := FindingComment{
+		PrNumber: 2, CommentID: 10, File: "a.go", CreatedAt: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		Category: "bug", Model: "gpt-4o", ThumbsUp: 2, Replies: 1, Resolved: true, Suggested: true, Applied: true,
+	}
+	if findings[0] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, findings[0])
+	}
+
+	if old := findings[1]; old.CommentID != 12 || old.ThumbsDown != 1 || old.Resolved || old.Suggested || old.Category != "" {
+		t.Fatalf("unexpected finding %+v", old)
+	}
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	gogithub "github.com/google/go-github/v33/github"
+)
+
+// WalkthroughMarker is a hidden HTML comment that identifies the walkthrough
+// comment of a pull request, so later runs edit it instead of adding another.
+const WalkthroughMarker = "<!-- powerpr:walkthrough -->"
+
+// Walkthrough is the high-level summary of a pull request generated by the
+// model.
+type Walkthrough struct {
+	Summary     string            `json:"summary"`
+	Files       []WalkthroughFile `json:"files"`
+	Risks       []string          `json:"risks"`
+	ReviewOrder []string          `json:"reviewOrder"`
+}
+
+type WalkthroughFile struct {
+	File    string `json:"file"`
+	Summary string `json:"summary"`
+}
+
+var tableEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// Markdown renders the walkthrough as the body of the walkthrough comment,
+// including WalkthroughMarker.
+func (w Walkthrough) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString(WalkthroughMarker + "\n## Walkthrough\n\n")
+	b.WriteString(strings.TrimSpace(w.Summary) + "\n")
+
+	if len(w.Files) > 0 {
+		b.WriteString("\n### Changes\n\n| File | Summary |\n| --- | --- |\n")
+		for _, f := range w.Files {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", tableEscaper.Replace(f.File), tableEscaper.Replace(f.Summary))
+		}
+	}
+
+	if len(w.Risks) > 0 {
+		b.WriteString("\n### Risk areas\n\n")
+		for _, risk := range w.Risks {
+			fmt.Fprintf(&b, "- %s\n", risk)
+		}
+	}
+
+	if len(w.ReviewOrder) > 0 {
+		b.WriteString("\n### Suggested review order\n\n")
+		for i, file := range w.ReviewOrder {
+			fmt.Fprintf(&b, "%d. `%s`\n", i+1, file)
+		}
+	}
+
+	return b.String()
+}
+
+// UpsertIssueComment edits the comment of the pull request conversation whose
+// body starts with marker, or creates a new comment when there is none. body
+// is expected to start with marker, so the comment is found on the next run.
+// Only the comments of the client's own account are edited, so anyone can
+// post the marker without taking over the comment.
+func (c *Client) UpsertIssueComment(prr PullRequestReviewRequest, marker, body string) (err error) {
+	var (
+		comments []*gogithub.IssueComment
+		login    string
+	)
+
+	if login, err = c.Login(); err != nil {
+		return
+	}
+
+	if comments, err = c.listIssueComments(prr.Owner, prr.Repo, prr.PrNumber); err != nil {
+		return
+	}
+
+	for _, comment := range comments {
+		if strings.HasPrefix(comment.GetBody(), marker) && strings.EqualFold(comment.GetUser().GetLogin(), login) {
+			_, _, err = c.Client.Issues.EditComment(c.ctx, prr.Owner, prr.Repo, comment.GetID(), &gogithub.IssueComment{Body: gogithub.String(body)})
+			return
+		}
+	}
+
+	_, _, err = c.Client.Issues.CreateComment(c.ctx, prr.Owner, prr.Repo, prr.PrNumber, &gogithub.IssueComment{Body: gogithub.String(body)})
+
+	return
+}
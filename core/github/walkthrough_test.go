@@ -0,0 +1,115 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_WalkthroughMarkdown(t *testing.T) {
+	w := Walkthrough{
+		Summary: "Adds a fibonacci helper.",
+		Files: []WalkthroughFile{
+			{File: "fibo.py", Summary: "New helper | naive version"},
+		},
+		Risks:       []string{"Exponential complexity for large inputs."},
+		ReviewOrder: []string{"fibo.py", "test.txt"},
+	}
+
+	expected := WalkthroughMarker + `
+## Walkthrough
+
+Adds a fibonacci helper.
+
+### Changes
+
+| File | Summary |
+| --- | --- |
+| ` + "`fibo.py`" + ` | New helper \| naive version |
+
+### Risk areas
+
+- Exponential complexity for large inputs.
+
+### Suggested review order
+
+1. ` + "`fibo.py`" + `
+2. ` + "`test.txt`" + `
+`
+
+	if got := w.Markdown(); got != expected {
+		t.Fatalf("unexpected markdown:\n%s", got)
+	}
+
+	if !strings.HasPrefix(Walkthrough{}.Markdown(), WalkthroughMarker) {
+		t.Fatal("an empty walkthrough must still carry the marker")
+	}
+}
+
+func Test_UpsertIssueComment(t *testing.T) {
+	var edited, created bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "powerpr-bot"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			created = true
+			fmt.Fprint(w, `{}`)
+			return
+		}
+
+		// The marker of someone else comes first
+		fmt.Fprintf(w, `[{"id": 1, "body": %q, "user": {"login": "mallory"}}, {"id": 2, "body": %q, "user": {"login": "PowerPR-Bot"}}]`,
+			WalkthroughMarker+" hijacked", WalkthroughMarker+" old")
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/comments/2", func(w http.ResponseWriter, r *http.Request) {
+		edited = r.Method == http.MethodPatch
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/comments/1", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("the comment of another user was edited")
+	})
+
+	c := newTestClient(t, mux)
+
+	if err := c.UpsertIssueComment(PullRequestReviewRequest{Owner: "owner", Repo: "repo", PrNumber: 1}, WalkthroughMarker, WalkthroughMarker+" new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !edited || created {
+		t.Fatalf("expected the comment of the bot to be edited, edited %v, created %v", edited, created)
+	}
+}
+
+func Test_Login(t *testing.T) {
+	var tests = []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"user token", http.StatusOK, `{"login": "powerpr-bot"}`, "powerpr-bot"},
+		{"actions token", http.StatusForbidden, `{"message": "Resource not accessible by integration"}`, ActionsBot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+
+			login, err := c.Login()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if login != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, login)
+			}
+		})
+	}
+}
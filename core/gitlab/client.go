@@ -0,0 +1,157 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/request"
+)
+
+// perPage is the size of the pages of the listings, the largest the API
+// accepts.
+const perPage = "100"
+
+var _ codehost.Host = (*Client)(nil)
+
+// Config holds the access token of the GitLab client and the instance it
+// talks to, https://gitlab.com when BaseURL is empty.
+type Config struct {
+	Token   string
+	BaseURL string
+}
+
+// Client reviews merge requests through the REST API (v4) of GitLab.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *request.Client
+	identity   *identity
+}
+
+// identity caches the username of the account of the token.
+type identity struct {
+	once     sync.Once
+	username string
+	err      error
+}
+
+func NewClient(cfg Config) (c Client, err error) {
+	c.token = cfg.Token
+	c.baseURL = APIBaseURL(cfg.BaseURL)
+	c.identity = &identity{}
+
+	c.httpClient, err = request.NewClient(request.ClientConfiguration{
+		CustomHttpClient: &http.Client{},
+	})
+
+	return
+}
+
+// APIBaseURL returns the REST API URL of a GitLab instance given either its
+// host URL, such as https://gitlab.example.com, or its API URL, such as
+// https://gitlab.example.com/api/v4, which GitLab CI sets in CI_API_V4_URL.
+func APIBaseURL(baseURL string) string {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/api/v4") {
+		return baseURL
+	}
+
+	return baseURL + "/api/v4"
+}
+
+// projectPath returns the path of the API of a project, identified by its
+// URL-encoded full path.
+func projectPath(owner, repo string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+// mergeRequestPath returns the path of the API of a merge request.
+func mergeRequestPath(owner, repo string, iid int) string {
+	return fmt.Sprintf("%s/merge_requests/%d", projectPath(owner, repo), iid)
+}
+
+// Login returns the username of the account of the token, which is a bot
+// user for project and group access tokens.
+func (c *Client) Login() (string, error) {
+	if c.identity == nil {
+		return c.fetchLogin()
+	}
+
+	c.identity.once.Do(func() {
+		c.identity.username, c.identity.err = c.fetchLogin()
+	})
+
+	return c.identity.username, c.identity.err
+}
+
+func (c *Client) fetchLogin() (username string, err error) {
+	var u user
+
+	if _, err = c.do(request.GET, "/user", nil, nil, &u); err != nil {
+		return
+	}
+
+	return u.Username, nil
+}
+
+// do sends a request to the API and decodes its response into v, unless v
+// is nil.
+func (c *Client) do(method, path string, params url.Values, body, v interface{}) (response request.Response, err error) {
+	if response, err = c.httpClient.Request(method, c.baseURL+path, request.Options{
+		Body:   body,
+		Params: params,
+		Headers: map[string]string{
+			"PRIVATE-TOKEN": c.token,
+			"Content-Type":  "application/json",
+		},
+	}); err != nil {
+		return
+	}
+
+	if response.Code < 200 || response.Code > 299 {
+		err = fmt.Errorf("%s %s: unexpected status %d: %s", method, path, response.Code, string(response.Body))
+		return
+	}
+
+	if v != nil {
+		err = json.Unmarshal(response.Body, v)
+	}
+
+	return
+}
+
+// list fetches every page of a listing, handing each one to decode.
+func (c *Client) list(path string, params url.Values, decode func(page []byte) error) (err error) {
+	var response request.Response
+
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("per_page", perPage)
+	params.Set("page", "1")
+
+	for {
+		if response, err = c.do(request.GET, path, params, nil, nil); err != nil {
+			return
+		}
+
+		if err = decode(response.Body); err != nil {
+			return
+		}
+
+		next := response.Header.Get("X-Next-Page")
+		if next == "" {
+			return
+		}
+		params.Set("page", next)
+	}
+}
@@ -0,0 +1,55 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/request"
+)
+
+type issue struct {
+	IID         int      `json:"iid"`
+	Title       string   `json:"title"`
+	State       string   `json:"state"`
+	Labels      []string `json:"labels"`
+	Description string   `json:"description"`
+}
+
+// GetLinkedIssues returns the issues the merge request closes when merged, as
+// found by GitLab in its description and commit messages.
+func (c *Client) GetLinkedIssues(prr github.PullRequestReviewRequest, pr content.PullRequest) (issues []content.Issue, err error) {
+	var closes []issue
+
+	if err = c.list(mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/closes_issues", nil, func(page []byte) error {
+		return appendPage(page, &closes)
+	}); err != nil {
+		return
+	}
+
+	for _, i := range closes {
+		issues = append(issues, content.Issue{
+			Reference: fmt.Sprintf("#%d", i.IID),
+			Closing:   true,
+			State:     i.State,
+			Title:     i.Title,
+			Labels:    i.Labels,
+			Body:      i.Description,
+		})
+	}
+
+	return
+}
+
+// GetFileContent returns the content of a file of the project at the given
+// ref.
+func (c *Client) GetFileContent(owner, repo, path, ref string) (content string, err error) {
+	var response request.Response
+
+	if response, err = c.do(request.GET, projectPath(owner, repo)+"/repository/files/"+url.PathEscape(path)+"/raw", url.Values{"ref": {ref}}, nil, nil); err != nil {
+		return
+	}
+
+	return string(response.Body), nil
+}
@@ -0,0 +1,335 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/request"
+)
+
+type mergeRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	SHA         string   `json:"sha"`
+	Labels      []string `json:"labels"`
+	WebURL      string   `json:"web_url"`
+	DiffRefs    diffRefs `json:"diff_refs"`
+}
+
+// diffRefs are the SHAs a diff position is relative to.
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+type commit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type diff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	Diff        string `json:"diff"`
+}
+
+type user struct {
+	Username string `json:"username"`
+}
+
+type discussion struct {
+	ID    string `json:"id"`
+	Notes []note `json:"notes"`
+}
+
+type note struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    user      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	System    bool      `json:"system"`
+	Position  *position `json:"position,omitempty"`
+}
+
+// position places a discussion on a line of the diff of a merge request.
+type position struct {
+	diffRefs
+	PositionType string `json:"position_type"`
+	OldPath      string `json:"old_path,omitempty"`
+	NewPath      string `json:"new_path,omitempty"`
+	OldLine      int    `json:"old_line,omitempty"`
+	NewLine      int    `json:"new_line,omitempty"`
+}
+
+func (c *Client) GetPullRequestChanges(prr github.PullRequestReviewRequest) (pr content.PullRequest, err error) {
+	var (
+		mr          mergeRequest
+		commits     []commit
+		discussions []discussion
+	)
+
+	if _, err = c.do(request.GET, mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber), nil, nil, &mr); err != nil {
+		return
+	}
+
+	if err = c.list(mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/commits", nil, func(page []byte) error {
+		return appendPage(page, &commits)
+	}); err != nil {
+		return
+	}
+
+	if discussions, err = c.listDiscussions(prr); err != nil {
+		return
+	}
+
+	pr = content.PullRequest{
+		Title:       mr.Title,
+		Description: mr.Description,
+		HeadSHA:     mr.DiffRefs.HeadSHA,
+		Labels:      mr.Labels,
+	}
+
+	if pr.HeadSHA == "" {
+		pr.HeadSHA = mr.SHA
+	}
+
+	// Commits are listed from the most recent one
+	for i := len(commits) - 1; i >= 0; i-- {
+		var diffs []diff
+		if err = c.list(fmt.Sprintf("%s/repository/commits/%s/diff", projectPath(prr.Owner, prr.Repo), commits[i].ID), nil, func(page []byte) error {
+			return appendPage(page, &diffs)
+		}); err != nil {
+			return
+		}
+
+		pr.Commits = append(pr.Commits, newCommit(commits[i], diffs, discussions, prr.MaxChangedLines))
+	}
+
+	return
+}
+
+// newCommit converts a commit and its diffs into the content model, attaching
+// to each file the diff notes made on it at that commit.
+func newCommit(cm commit, diffs []diff, discussions []discussion, maxChangedLines int) (cc content.Commit) {
+	cc = content.Commit{
+		SHA:     cm.ID,
+		Message: cm.Message,
+	}
+
+	for _, d := range diffs {
+		additions, deletions := countChanges(d.Diff)
+		if additions+deletions > maxChangedLines {
+			continue
+		}
+
+		cf := content.File{
+			Filename:  d.NewPath,
+			Status:    fileStatus(d),
+			Additions: additions,
+			Deletions: deletions,
+			Changes:   additions + deletions,
+			Hunks:     content.ParsePatch(d.Diff),
+		}
+
+		if d.RenamedFile {
+			cf.PreviousFilename = d.OldPath
+		}
+
+		for _, dd := range discussions {
+			for _, n := range dd.Notes {
+				if n.System || n.Position == nil || n.Position.NewPath != d.NewPath || n.Position.HeadSHA != cm.ID {
+					continue
+				}
+
+				line := n.Position.NewLine
+				if line == 0 {
+					line = n.Position.OldLine
+				}
+
+				cf.Comments = append(cf.Comments, content.Comment{
+					Line: line,
+					User: n.Author.Username,
+					Body: n.Body,
+				})
+			}
+		}
+
+		cc.Files = append(cc.Files, cf)
+	}
+
+	return
+}
+
+// fileStatus returns the status of a file in the terms of GitHub, which the
+// rendering of the pull request uses.
+func fileStatus(d diff) string {
+	switch {
+	case d.NewFile:
+		return "added"
+	case d.DeletedFile:
+		return "removed"
+	case d.RenamedFile:
+		return "renamed"
+	}
+
+	return "modified"
+}
+
+// countChanges counts the added and the deleted lines of a diff.
+func countChanges(patch string) (additions, deletions int) {
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+
+	return
+}
+
+// PullRequestReview posts the findings as diff discussions on the new version
+// of the files, followed by prr.Comment as a note of the merge request.
+// Findings spanning several lines are placed on their last line, and their
+// suggestion replaces all of their lines. Findings on lines outside of the
+// diff are listed in the note instead. A discussion that fails does not keep
+// the others and the note from being posted: the errors are returned
+// together.
+func (c *Client) PullRequestReview(prr github.PullRequestReviewRequest) (err error) {
+	var (
+		mr      mergeRequest
+		diffs   []diff
+		errs    []error
+		outside []string
+	)
+
+	if _, err = c.do(request.GET, mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber), nil, nil, &mr); err != nil {
+		return
+	}
+
+	if err = c.list(mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/diffs", nil, func(page []byte) error {
+		return appendPage(page, &diffs)
+	}); err != nil {
+		return
+	}
+
+	for _, value := range prr.Reviews.Review {
+		body := reviewBody(value, prr.Model)
+		if body == "" {
+			continue
+		}
+
+		pos, ok := findingPosition(diffs, value)
+		if !ok {
+			outside = append(outside, fmt.Sprintf("- `%s:%d`: %s", value.File, value.LineNumber, body))
+			continue
+		}
+		pos.diffRefs = mr.DiffRefs
+
+		if _, postErr := c.do(request.POST, mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/discussions", nil, map[string]interface{}{
+			"body":     body,
+			"position": pos,
+		}, nil); postErr != nil {
+			errs = append(errs, fmt.Errorf("commenting on %s:%d: %w", value.File, value.LineNumber, postErr))
+		}
+	}
+
+	comment := prr.Comment
+	if len(outside) > 0 {
+		comment += "\n\nThe following findings are on lines outside of the diff:\n\n" + strings.Join(outside, "\n")
+	}
+
+	if _, err = c.do(request.POST, mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/notes", nil, map[string]string{
+		"body": comment,
+	}, nil); err != nil {
+		errs = append(errs, fmt.Errorf("posting the summary note: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// findingPosition places a finding on the line of the diff of its file it
+// ends on. Added lines only have a new line, and unchanged lines both an old
+// and a new one, as GitLab expects. ok is false when the line is not in the
+// diff.
+func findingPosition(diffs []diff, finding github.Review) (pos position, ok bool) {
+	for _, d := range diffs {
+		if d.NewPath != finding.File {
+			continue
+		}
+
+		var oldLine int
+		if oldLine, ok = content.OldLine(content.ParsePatch(d.Diff), finding.LineNumber); !ok {
+			return
+		}
+
+		return position{
+			PositionType: "text",
+			OldPath:      d.OldPath,
+			NewPath:      d.NewPath,
+			OldLine:      oldLine,
+			NewLine:      finding.LineNumber,
+		}, true
+	}
+
+	return
+}
+
+// reviewBody returns the comment of a finding, with its suggestion in the
+// format of GitLab and its ID.
+func reviewBody(r github.Review, model string) (body string) {
+	body = r.ReviewComment
+
+	if r.SuggestionComments != "" {
+		start, end := r.Lines()
+		body += fmt.Sprintf("\n```suggestion:-%d+0\n%s\n```", end-start, r.SuggestionComments)
+	}
+
+	if body != "" {
+		body += github.FindingFooter(r, model)
+	}
+
+	return
+}
+
+// CreatePullRequest opens a merge request from the Head branch into the Base
+// branch and returns its URL.
+func (c *Client) CreatePullRequest(npr github.NewPullRequest) (url string, err error) {
+	var mr mergeRequest
+
+	if _, err = c.do(request.POST, projectPath(npr.Owner, npr.Repo)+"/merge_requests", nil, map[string]string{
+		"source_branch": npr.Head,
+		"target_branch": npr.Base,
+		"title":         npr.Title,
+		"description":   npr.Description,
+	}, &mr); err != nil {
+		return
+	}
+
+	return mr.WebURL, nil
+}
+
+// appendPage decodes a page of a listing and appends it to v, a pointer to a
+// slice.
+func appendPage[T any](page []byte, v *[]T) (err error) {
+	var items []T
+
+	if err = json.Unmarshal(page, &items); err != nil {
+		return
+	}
+
+	*v = append(*v, items...)
+
+	return
+}
@@ -0,0 +1,232 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+// newTestClient returns a client that sends its requests to handler.
+func newTestClient(t *testing.T, handler http.Handler) Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(Config{Token: "token", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func Test_APIBaseURL(t *testing.T) {
+	var tests = []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{"gitlab.com", "", "https://gitlab.com/api/v4"},
+		{"host URL", "https://gitlab.example.com/", "https://gitlab.example.com/api/v4"},
+		{"API URL", "https://gitlab.example.com/api/v4", "https://gitlab.example.com/api/v4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if url := APIBaseURL(tt.baseURL); url != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, url)
+			}
+		})
+	}
+}
+
+func Test_GetPullRequestChanges(t *testing.T) {
+	const mr = "/api/v4/projects/group%2Fsub%2Fproject/merge_requests/7"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(mr, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			t.Errorf("missing token")
+		}
+		fmt.Fprint(w, `{"title":"Add b","description":"Closes #1","sha":"s2","diff_refs":{"base_sha":"b","start_sha":"s","head_sha":"s2"}}`)
+	})
+	mux.HandleFunc(mr+"/commits", func(w http.ResponseWriter, r *http.Request) {
+		// Commits span two pages, the most recent first
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"id":"s2","message":"second"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":"s1","message":"first"}]`)
+	})
+	mux.HandleFunc(mr+"/discussions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id":"d1","notes":[{"id":1,"body":"rename it","author":{"username":"ana"},"position":{"head_sha":"s2","new_path":"b.go","new_line":2}}]},
+			{"id":"d2","notes":[{"id":2,"body":"added 1 commit","system":true}]}
+		]`)
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fsub%2Fproject/repository/commits/s1/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"old_path":"a.go","new_path":"a.go","diff":"@@ -1,2 +1,2 @@\n-a\n+b\n c\n"}]`)
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fsub%2Fproject/repository/commits/s2/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"old_path":"b.go","new_path":"b.go","new_file":true,"diff":"@@ -0,0 +1,2 @@\n+b\n+c\n"}]`)
+	})
+
+	c := newTestClient(t, mux)
+
+	pr, err := c.GetPullRequestChanges(github.PullRequestReviewRequest{Owner: "group/sub", Repo: "project", PrNumber: 7, MaxChangedLines: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pr.Title != "Add b" || pr.HeadSHA != "s2" || len(pr.Commits) != 2 {
+		t.Fatalf("unexpected pull request %+v", pr)
+	}
+
+	if pr.Commits[0].SHA != "s1" || pr.Commits[1].SHA != "s2" {
+		t.Fatalf("expected commits in chronological order, got %s and %s", pr.Commits[0].SHA, pr.Commits[1].SHA)
+	}
+
+	first := pr.Commits[0].Files[0]
+	if first.Status != "modified" || first.Additions != 1 || first.Deletions != 1 || len(first.Hunks) != 1 || len(first.Comments) != 0 {
+		t.Fatalf("unexpected file %+v", first)
+	}
+
+	second := pr.Commits[1].Files[0]
+	if second.Status != "added" || second.Additions != 2 || len(second.Comments) != 1 || second.Comments[0].Line != 2 || second.Comments[0].User != "ana" {
+		t.Fatalf("unexpected file %+v", second)
+	}
+}
+
+func Test_PullRequestReview(t *testing.T) {
+	const mr = "/api/v4/projects/group%2Fproject/merge_requests/7"
+
+	var (
+		positions []position
+		bodies    []string
+		summary   string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(mr, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"diff_refs":{"base_sha":"b","start_sha":"s","head_sha":"h"}}`)
+	})
+	mux.HandleFunc(mr+"/diffs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"old_path": "a.go", "new_path": "a.go", "diff": "@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+			{"old_path": "b.go", "new_path": "b.go", "diff": "@@ -4,0 +4,3 @@\n+d\n+e\n+f\n"},
+			{"old_path": "d.go", "new_path": "e.go", "renamed_file": true, "diff": "@@ -1,2 +1,2 @@\n-g\n+h\n i\n"},
+			{"old_path": "x.go", "new_path": "x.go", "diff": "@@ -1 +1 @@\n-y\n+z\n"}
+		]`)
+	})
+	mux.HandleFunc(mr+"/discussions", func(w http.ResponseWriter, r *http.Request) {
+		var d struct {
+			Body     string   `json:"body"`
+			Position position `json:"position"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			t.Error(err)
+		}
+		if d.Position.NewPath == "x.go" {
+			http.Error(w, `{"message":"500 Internal Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+		positions = append(positions, d.Position)
+		bodies = append(bodies, d.Body)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc(mr+"/notes", func(w http.ResponseWriter, r *http.Request) {
+		var n note
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Error(err)
+		}
+		summary = n.Body
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+
+	c := newTestClient(t, mux)
+
+	err := c.PullRequestReview(github.PullRequestReviewRequest{
+		Owner:    "group",
+		Repo:     "project",
+		PrNumber: 7,
+		Comment:  "summary",
+		Reviews: github.Reviews{Review: []github.Review{
+			{File: "x.go", LineNumber: 1, ReviewComment: "fails"},
+			{File: "a.go", LineNumber: 3, ReviewComment: "check the error"},
+			{File: "b.go", StartLine: 4, LineNumber: 6, ReviewComment: "simplify", SuggestionComments: "return nil"},
+			{File: "c.go", LineNumber: 1},
+			{File: "e.go", LineNumber: 2, ReviewComment: "rename the test too"},
+			{File: "a.go", LineNumber: 40, ReviewComment: "outside of the diff"},
+		}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "commenting on x.go:1") {
+		t.Fatalf("expected the error of x.go, got %v", err)
+	}
+
+	if len(positions) != 3 {
+		t.Fatalf("expected 3 discussions after the failed one, got %d", len(positions))
+	}
+
+	refs := diffRefs{BaseSHA: "b", StartSHA: "s", HeadSHA: "h"}
+	for i, expected := range []position{
+		{diffRefs: refs, PositionType: "text", OldPath: "a.go", NewPath: "a.go", NewLine: 3},
+		{diffRefs: refs, PositionType: "text", OldPath: "b.go", NewPath: "b.go", NewLine: 6},
+		{diffRefs: refs, PositionType: "text", OldPath: "d.go", NewPath: "e.go", OldLine: 2, NewLine: 2},
+	} {
+		if positions[i] != expected {
+			t.Fatalf("expected position %+v, got %+v", expected, positions[i])
+		}
+	}
+
+	finding := github.Review{File: "b.go", StartLine: 4, LineNumber: 6}
+	if bodies[1] != "simplify\n```suggestion:-2+0\nreturn nil\n```"+github.FindingFooter(finding, "") {
+		t.Fatalf("unexpected body %q", bodies[1])
+	}
+
+	if !strings.HasPrefix(summary, "summary\n\n") || !strings.Contains(summary, "- `a.go:40`: outside of the diff") {
+		t.Fatalf("expected the summary note to list the finding outside of the diff, got %q", summary)
+	}
+}
+
+func Test_UpsertIssueComment(t *testing.T) {
+	const mr = "/api/v4/projects/group%2Fproject/merge_requests/7"
+
+	var edited bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"username": "project_1_bot"}`)
+	})
+	mux.HandleFunc(mr+"/notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			t.Errorf("expected the note of the bot to be edited")
+		}
+		fmt.Fprint(w, `[{"id": 1, "body": "<!-- marker --> hijacked", "author": {"username": "mallory"}}, {"id": 2, "body": "<!-- marker --> old", "author": {"username": "project_1_bot"}}]`)
+	})
+	mux.HandleFunc(mr+"/notes/2", func(w http.ResponseWriter, r *http.Request) {
+		edited = r.Method == http.MethodPut
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc(mr+"/notes/1", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("the note of another user was edited")
+	})
+
+	c := newTestClient(t, mux)
+
+	if err := c.UpsertIssueComment(github.PullRequestReviewRequest{Owner: "group", Repo: "project", PrNumber: 7}, "<!-- marker -->", "<!-- marker --> new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !edited {
+		t.Fatal("expected the note of the bot to be edited")
+	}
+}
@@ -0,0 +1,82 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/request"
+)
+
+func (c *Client) listDiscussions(prr github.PullRequestReviewRequest) (discussions []discussion, err error) {
+	err = c.list(mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/discussions", nil, func(page []byte) error {
+		return appendPage(page, &discussions)
+	})
+
+	return
+}
+
+// GetConversation returns the notes of the merge request that are not on a
+// line of its diff, in chronological order. System notes, such as the ones
+// recording new commits, are left out.
+func (c *Client) GetConversation(prr github.PullRequestReviewRequest) (posts []content.Post, err error) {
+	var discussions []discussion
+
+	if discussions, err = c.listDiscussions(prr); err != nil {
+		return
+	}
+
+	for _, d := range discussions {
+		for _, n := range d.Notes {
+			if n.System || n.Position != nil {
+				continue
+			}
+
+			posts = append(posts, content.Post{
+				Kind:      content.PostComment,
+				Author:    n.Author.Username,
+				CreatedAt: n.CreatedAt,
+				Body:      n.Body,
+			})
+		}
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	return
+}
+
+// UpsertIssueComment edits the note of the merge request that starts with
+// marker, or posts body as a new note when there is none. Only the notes of
+// the client's own account are edited.
+func (c *Client) UpsertIssueComment(prr github.PullRequestReviewRequest, marker, body string) (err error) {
+	var (
+		notes []note
+		login string
+	)
+
+	if login, err = c.Login(); err != nil {
+		return
+	}
+
+	if err = c.list(mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/notes", nil, func(page []byte) error {
+		return appendPage(page, &notes)
+	}); err != nil {
+		return
+	}
+
+	for _, n := range notes {
+		if strings.HasPrefix(n.Body, marker) && strings.EqualFold(n.Author.Username, login) {
+			_, err = c.do(request.PUT, fmt.Sprintf("%s/notes/%d", mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber), n.ID), nil, map[string]string{"body": body}, nil)
+			return
+		}
+	}
+
+	_, err = c.do(request.POST, mergeRequestPath(prr.Owner, prr.Repo, prr.PrNumber)+"/notes", nil, map[string]string{"body": body}, nil)
+
+	return
+}
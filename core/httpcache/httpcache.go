@@ -0,0 +1,170 @@
+// Package httpcache caches the responses of an HTTP API with their
+// validators, so repeated requests are answered by conditional requests
+// that do not send the body again.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheHeader is set on the responses served from the cache.
+const CacheHeader = "X-From-Cache"
+
+var (
+	// immutablePath matches the paths of the resources identified by full
+	// commit SHAs, which never change.
+	immutablePath = regexp.MustCompile(`/(commits|compare)/[0-9a-f]{40}(\.\.\.[0-9a-f]{40})?$`)
+	fullSHA       = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Header http.Header
+	Body   []byte
+	// Immutable entries are served without asking the server.
+	Immutable bool
+	StoredAt  time.Time
+}
+
+// Store keeps the entries of the cache.
+type Store interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry)
+}
+
+// Transport is a RoundTripper that caches the successful responses of GET
+// requests that carry an ETag or a Last-Modified header, and revalidates them
+// with If-None-Match and If-Modified-Since. A 304 answer is turned into the
+// cached response. Responses about a commit SHA are kept and served without
+// revalidation. The entries are kept apart by Identity, the credential the
+// requests are sent with, so a store shared on disk never serves a response
+// to a caller that could not have fetched it.
+type Transport struct {
+	Base     http.RoundTripper
+	Store    Store
+	Identity string
+}
+
+func NewTransport(base http.RoundTripper, store Store, identity string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base, Store: store, Identity: identity}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	// Requests that are already conditional are left to their sender
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	var (
+		key           = Key(req, t.Identity)
+		immutable     = Immutable(req)
+		cached, found = t.Store.Get(key)
+	)
+
+	if found && cached.Immutable {
+		return cached.response(req), nil
+	}
+
+	outgoing := req
+	if found {
+		outgoing = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			outgoing.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	if resp, err = t.Base.RoundTrip(outgoing); err != nil {
+		return
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		// The 304 carries fresh headers, such as the rate limit ones
+		for name, values := range resp.Header {
+			cached.Header[name] = values
+		}
+		cached.StoredAt = time.Now()
+		t.Store.Set(key, cached)
+
+		return cached.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !(immutable || resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		return
+	}
+
+	var body []byte
+	body, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.Store.Set(key, Entry{
+		Header:    resp.Header.Clone(),
+		Body:      body,
+		Immutable: immutable,
+		StoredAt:  time.Now(),
+	})
+
+	return
+}
+
+// response returns the entry as the response to req.
+func (e Entry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheHeader, "1")
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// Key returns the key of the cache entry of a request sent with identity.
+func Key(req *http.Request, identity string) string {
+	return identity + " " + req.URL.String() + " " + req.Header.Get("Accept")
+}
+
+// Identity returns the hash of a credential, to key the entries of its
+// requests without keeping the credential in the store.
+func Identity(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Immutable reports whether a request is about full commit SHAs: a commit, a
+// comparison between two commits or a file at a commit.
+func Immutable(req *http.Request) bool {
+	if immutablePath.MatchString(strings.TrimSuffix(req.URL.Path, "/")) {
+		return true
+	}
+
+	return strings.Contains(req.URL.Path, "/contents/") && fullSHA.MatchString(req.URL.Query().Get("ref"))
+}
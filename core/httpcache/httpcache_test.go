@@ -0,0 +1,164 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const sha = "0123456789abcdef0123456789abcdef01234567"
+
+func Test_Transport(t *testing.T) {
+	var tests = []struct {
+		name             string
+		path             string
+		validators       bool
+		expectedRequests int
+		expectedCached   bool
+	}{
+		{"revalidated with the ETag", "/repos/owner/repo/pulls/1", true, 2, true},
+		{"commit kept without revalidation", "/repos/owner/repo/commits/" + sha, true, 1, true},
+		{"file at a commit kept without revalidation", "/repos/owner/repo/contents/main.go?ref=" + sha, false, 1, true},
+		{"not cached without validators", "/repos/owner/repo/pulls/1", false, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests, notModified int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(5000-requests))
+
+				if tt.validators {
+					if r.Header.Get("If-None-Match") == `"v1"` {
+						notModified++
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+					w.Header().Set("ETag", `"v1"`)
+				}
+
+				fmt.Fprint(w, "body")
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: NewTransport(nil, NewMemoryStore(0), Identity("token"))}
+
+			var resp *http.Response
+			for i := 0; i < 2; i++ {
+				var err error
+				if resp, err = client.Get(server.URL + tt.path); err != nil {
+					t.Fatal(err)
+				}
+
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK || string(body) != "body" {
+					t.Fatalf("unexpected response %d %q", resp.StatusCode, body)
+				}
+			}
+
+			if requests != tt.expectedRequests {
+				t.Fatalf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+
+			if cached := resp.Header.Get(CacheHeader) != ""; cached != tt.expectedCached {
+				t.Fatalf("expected cached to be %v", tt.expectedCached)
+			}
+
+			if tt.expectedRequests == 2 && tt.expectedCached && (notModified != 1 || resp.Header.Get("X-RateLimit-Remaining") != "4998") {
+				t.Fatalf("expected the headers of the 304, got %d 304s and %s remaining", notModified, resp.Header.Get("X-RateLimit-Remaining"))
+			}
+		})
+	}
+}
+
+func Test_TransportIdentities(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "body")
+	}))
+	defer server.Close()
+
+	store := NewMemoryStore(0)
+
+	var tests = []struct {
+		name             string
+		identity         string
+		expectedRequests int
+		expectedCached   bool
+	}{
+		{"first fetch", Identity("token a"), 1, false},
+		{"served to the same identity", Identity("token a"), 1, true},
+		{"fetched again by another identity", Identity("token b"), 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: NewTransport(nil, store, tt.identity)}
+
+			resp, err := client.Get(server.URL + "/repos/owner/repo/commits/" + sha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if requests != tt.expectedRequests {
+				t.Fatalf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+
+			if cached := resp.Header.Get(CacheHeader) != ""; cached != tt.expectedCached {
+				t.Fatalf("expected cached to be %v", tt.expectedCached)
+			}
+		})
+	}
+}
+
+func Test_Stores(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name  string
+		store Store
+	}{
+		{"memory", NewMemoryStore(2)},
+		{"disk", disk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, found := tt.store.Get("a"); found {
+				t.Fatal("expected no entry")
+			}
+
+			tt.store.Set("a", Entry{Header: http.Header{"Etag": {`"v1"`}}, Body: []byte("body"), Immutable: true})
+
+			entry, found := tt.store.Get("a")
+			if !found || string(entry.Body) != "body" || entry.Header.Get("ETag") != `"v1"` || !entry.Immutable {
+				t.Fatalf("unexpected entry %+v", entry)
+			}
+		})
+	}
+}
+
+func Test_MemoryStoreEviction(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		store.Set(key, Entry{})
+	}
+
+	for key, expected := range map[string]bool{"a": false, "b": true, "c": true} {
+		if _, found := store.Get(key); found != expected {
+			t.Fatalf("expected %s to be kept: %v", key, expected)
+		}
+	}
+}
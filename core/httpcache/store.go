@@ -0,0 +1,116 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultMemoryEntries is the number of entries of a memory store created
+// with a size of zero.
+const DefaultMemoryEntries = 1000
+
+// MemoryStore keeps up to a number of entries in memory, evicting the ones
+// stored first.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]Entry
+	order   []string
+}
+
+func NewMemoryStore(size int) *MemoryStore {
+	if size <= 0 {
+		size = DefaultMemoryEntries
+	}
+
+	return &MemoryStore{size: size, entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, found := s.entries[key]
+	return entry, found
+}
+
+func (s *MemoryStore) Set(key string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.entries[key]; !found {
+		s.order = append(s.order, key)
+	}
+	s.entries[key] = entry
+
+	for len(s.order) > s.size {
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// DiskStore keeps the entries as files of a directory, so they are shared by
+// the runs of the program.
+type DiskStore struct {
+	dir string
+}
+
+func NewDiskStore(dir string) (s *DiskStore, err error) {
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the entry of key. Unreadable entries are treated as missing.
+func (s *DiskStore) Get(key string) (entry Entry, found bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false
+	}
+
+	return entry, true
+}
+
+// Set writes the entry of key. The cache is best effort: entries that cannot
+// be written are dropped.
+func (s *DiskStore) Set(key string, entry Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if err = os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,120 @@
+// Package logging builds the zap logger shared by every command, which masks
+// the secrets of its log lines before they are written.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log formats.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Config holds the settings of the logger. Secrets are values masked
+// wherever they appear, such as the tokens the process was given, and
+// Patterns are regular expressions of more secrets to mask, in addition to
+// the built-in ones.
+type Config struct {
+	Level    string
+	Format   string
+	Secrets  []string
+	Patterns []string
+}
+
+// New returns a logger writing to w at cfg.Level, in cfg.Format, that
+// redacts the messages, fields and stack traces of its entries.
+func New(cfg Config, w io.Writer) (logger *zap.Logger, err error) {
+	var (
+		level    zapcore.Level
+		encoder  zapcore.Encoder
+		redactor *Redactor
+	)
+
+	if cfg.Level == "" {
+		cfg.Level = "info"
+	}
+
+	if err = level.UnmarshalText([]byte(strings.ToLower(cfg.Level))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", cfg.Level)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected %s or %s", cfg.Format, FormatJSON, FormatConsole)
+	}
+
+	if redactor, err = NewRedactor(cfg.Patterns, cfg.Secrets); err != nil {
+		return
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(w)), level)
+
+	return zap.New(&redactingCore{Core: core, redactor: redactor}, zap.AddCaller()), nil
+}
+
+// redactingCore masks the secrets of the entries before they reach Core.
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.Redact(entry.Message)
+	entry.Stack = c.redactor.Redact(entry.Stack)
+
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields returns the fields with their text redacted. Fields that
+// are not text, such as errors and values encoded by reflection, are turned
+// into text first, so nothing escapes the redaction.
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = c.redactor.Redact(field.String)
+		case zapcore.ByteStringType, zapcore.BinaryType:
+			field = zap.String(field.Key, c.redactor.Redact(string(field.Interface.([]byte))))
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok && err != nil {
+				field = zap.String(field.Key, c.redactor.Redact(err.Error()))
+			}
+		case zapcore.StringerType, zapcore.ReflectType, zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+			field = zap.String(field.Key, c.redactor.Redact(fmt.Sprintf("%+v", field.Interface)))
+		}
+
+		redacted[i] = field
+	}
+
+	return redacted
+}
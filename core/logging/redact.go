@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces the secrets of the log lines.
+const Mask = "[REDACTED]"
+
+// minSecretLength is the length below which the values given as secrets are
+// not masked, so short values such as "true" do not mask every line.
+const minSecretLength = 8
+
+// builtinPatterns match the secrets that end up in logs: credentials of
+// HTTP headers and URLs, tokens of the supported services and private keys.
+// Only the "secret" group is masked when a pattern has one.
+var builtinPatterns = []string{
+	`(?i)\b(?:proxy-)?authorization["']?\s*[:=]\s*["'\[]?(?:(?:bearer|token|basic)\s+)?(?P<secret>[^\s"',\]]+)`,
+	`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`,
+	`(?i)\b(?:private-token|x-api-key|api[_-]?key|access[_-]?token|client[_-]?secret|password|secret|token)["']?\s*[:=]\s*["'\[]?(?P<secret>[^\s"'&,\]]{6,})`,
+	`://[^/\s:@]+:(?P<secret>[^/\s@]+)@`,
+	`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`,
+	`\bglpat-[A-Za-z0-9_\-]{20,}\b`,
+	`\bsk-[A-Za-z0-9_\-]{20,}\b`,
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`,
+	`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`,
+}
+
+// Redactor masks secrets in text.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	secrets  []string
+}
+
+// NewRedactor returns a redactor of the built-in patterns, patterns and the
+// literal secrets.
+func NewRedactor(patterns, secrets []string) (r *Redactor, err error) {
+	r = &Redactor{}
+
+	for _, pattern := range append(builtinPatterns[:len(builtinPatterns):len(builtinPatterns)], patterns...) {
+		var re *regexp.Regexp
+
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+
+		r.patterns = append(r.patterns, re)
+	}
+
+	for _, secret := range secrets {
+		if secret = strings.TrimSpace(secret); len(secret) >= minSecretLength {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+
+	// The longest secrets first, so a secret containing another one is
+	// masked whole.
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+
+	return
+}
+
+// Redact returns s with its secrets replaced by Mask.
+func (r *Redactor) Redact(s string) string {
+	if s == "" {
+		return s
+	}
+
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+
+	for _, re := range r.patterns {
+		s = replace(re, s)
+	}
+
+	return s
+}
+
+// replace masks the matches of re in s, or only their "secret" group when re
+// has one.
+func replace(re *regexp.Regexp, s string) string {
+	var (
+		b       strings.Builder
+		last    int
+		group   = re.SubexpIndex("secret")
+		matches = re.FindAllStringSubmatchIndex(s, -1)
+	)
+
+	if len(matches) == 0 {
+		return s
+	}
+
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if group > 0 && match[2*group] >= 0 {
+			start, end = match[2*group], match[2*group+1]
+		}
+
+		b.WriteString(s[last:start])
+		b.WriteString(Mask)
+		last = end
+	}
+
+	b.WriteString(s[last:])
+
+	return b.String()
+}
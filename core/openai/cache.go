@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache keeps chat completions in files of a directory, keyed by a hash of
+// their request, so a retried run does not pay for the same completion
+// again. The directory can be kept between the runs of a workflow with
+// actions/cache.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+type cacheEntry struct {
+	StoredAt time.Time              `json:"storedAt"`
+	Response ChatCompletionResponse `json:"response"`
+}
+
+// NewCache returns a cache in dir whose entries expire after ttl, or never
+// when ttl is zero.
+func NewCache(dir string, ttl time.Duration) (c *Cache, err error) {
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+
+	return &Cache{dir: dir, ttl: ttl}, nil
+}
+
+// CacheKey returns the key of a request: the SHA-256 of the request as it is
+// sent, which covers the model, the messages, the temperature and any other
+// parameter.
+func CacheKey(chatCompletion ChatCompletionRequest) (key string, err error) {
+	var body []byte
+
+	if body, err = json.Marshal(chatCompletion); err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Get returns the response stored for key, unless it has expired.
+func (c *Cache) Get(key string) (response ChatCompletionResponse, found bool) {
+	var entry cacheEntry
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return
+	}
+
+	if c.ttl > 0 && time.Since(entry.StoredAt) > c.ttl {
+		return
+	}
+
+	return entry.Response, true
+}
+
+// Set stores the response of key.
+func (c *Cache) Set(key string, response ChatCompletionResponse) (err error) {
+	var (
+		data []byte
+		tmp  *os.File
+	)
+
+	if data, err = json.Marshal(cacheEntry{StoredAt: time.Now(), Response: response}); err != nil {
+		return
+	}
+
+	if tmp, err = os.CreateTemp(c.dir, ".entry-*"); err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+
+	if err = tmp.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(tmp.Name(), c.path(key))
+}
@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_CreateChatCompletionCache(t *testing.T) {
+	var request = ChatCompletionRequest{
+		Model:       "gpt-4o",
+		Messages:    []ChatMessages{{Role: "user", Content: "review"}},
+		Temperature: 0.5,
+	}
+
+	var tests = []struct {
+		name             string
+		ttl              time.Duration
+		wait             time.Duration
+		second           func(ChatCompletionRequest) ChatCompletionRequest
+		expectedRequests int
+	}{
+		{"same request", time.Hour, 0, nil, 1},
+		{"other temperature", time.Hour, 0, func(r ChatCompletionRequest) ChatCompletionRequest { r.Temperature = 0; return r }, 2},
+		{"other model", time.Hour, 0, func(r ChatCompletionRequest) ChatCompletionRequest { r.Model = "gpt-4o-mini"; return r }, 2},
+		{"expired", time.Millisecond, 10 * time.Millisecond, nil, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				fmt.Fprintf(w, `{"id":"%d","choices":[{"message":{"role":"assistant","content":"{}"}}]}`, requests)
+			}))
+			defer server.Close()
+
+			cache, err := NewCache(t.TempDir(), tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewClient(Config{Key: "key", OpenAIUrl: server.URL, Cache: cache})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first, err := c.CreateChatCompletion(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(tt.wait)
+
+			second := request
+			if tt.second != nil {
+				second = tt.second(request)
+			}
+
+			response, err := c.CreateChatCompletion(second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if requests != tt.expectedRequests {
+				t.Fatalf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+
+			if tt.expectedRequests == 1 && response.ID != first.ID {
+				t.Fatalf("expected the cached response %s, got %s", first.ID, response.ID)
+			}
+		})
+	}
+}
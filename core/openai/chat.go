@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/lucasmbaia/power-actions/request"
+	"go.uber.org/zap"
 )
 
 type ErrorResponse struct {
@@ -49,8 +50,22 @@ type Speech struct {
 func (c *Client) CreateChatCompletion(chatCompletion ChatCompletionRequest) (response ChatCompletionResponse, err error) {
 	var (
 		httpResponse request.Response
+		key          string
+		found        bool
 	)
 
+	if c.cache != nil {
+		if key, err = CacheKey(chatCompletion); err != nil {
+			return
+		}
+
+		if response, found = c.cache.Get(key); found {
+			c.logger.Info("Chat completion cache hit", zap.String("key", key))
+			return
+		}
+		c.logger.Info("Chat completion cache miss", zap.String("key", key))
+	}
+
 	if httpResponse, err = c.httpClient.Request(request.POST, fmt.Sprintf("%s/v1/chat/completions", c.openAiUrl), request.Options{
 		Body: chatCompletion,
 		Headers: map[string]string{
@@ -62,7 +77,14 @@ func (c *Client) CreateChatCompletion(chatCompletion ChatCompletionRequest) (res
 	}
 
 	if httpResponse.Code == http.StatusOK {
-		err = json.Unmarshal(httpResponse.Body, &response)
+		if err = json.Unmarshal(httpResponse.Body, &response); err != nil || c.cache == nil {
+			return
+		}
+
+		// A run that can not store its completion still succeeds
+		if cacheErr := c.cache.Set(key, response); cacheErr != nil {
+			c.logger.Warn("Error to cache the chat completion", zap.Error(cacheErr))
+		}
 	} else {
 		var errorResponse ErrorResponse
 		if err = json.Unmarshal(httpResponse.Body, &errorResponse); err != nil {
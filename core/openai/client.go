@@ -4,17 +4,30 @@ import (
 	"fmt"
 
 	"github.com/lucasmbaia/power-actions/request"
+	"go.uber.org/zap"
 )
 
+// Provider is the name of the provider of the models, which the
+// data-governance policy allows per repository.
+const Provider = "openai"
+
 type Client struct {
 	key        string
 	httpClient *request.Client
 	openAiUrl  string
+	cache      *Cache
+	logger     *zap.Logger
 }
 
 type Config struct {
 	Key       string
 	OpenAIUrl string
+
+	// Cache, when set, answers the chat completions that were already made.
+	Cache *Cache
+
+	// Logger logs the use of the cache. Nothing is logged when it is nil.
+	Logger *zap.Logger
 }
 
 func NewClient(cfg Config) (c Client, err error) {
@@ -32,6 +45,17 @@ func NewClient(cfg Config) (c Client, err error) {
 		return
 	}
 	c.key = cfg.Key
+	c.cache = cfg.Cache
+
+	if c.logger = cfg.Logger; c.logger == nil {
+		c.logger = zap.NewNop()
+	}
 
 	return
 }
+
+// SetCache replaces the cache of the chat completions. A nil cache disables
+// it.
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
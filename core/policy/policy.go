@@ -0,0 +1,271 @@
+// Package policy enforces the data-governance policy: the files and the
+// content never sent to a remote model, and the providers of the models each
+// repository may be sent to. The secrets are redacted with it, so whatever
+// goes through Apply is safe to send.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/profile"
+	"github.com/lucasmbaia/power-actions/core/secrets"
+)
+
+// Placeholder replaces the text of the pull request matching a content
+// pattern, outside of the files.
+const Placeholder = "[WITHHELD]"
+
+// Policy is the data-governance policy, read from the policy key of the
+// configuration file.
+type Policy struct {
+	// Paths are glob patterns of the files never sent, where ** matches any
+	// number of directories. Patterns without a slash match the base name of
+	// the files.
+	Paths []string `mapstructure:"paths"`
+
+	// Patterns are regular expressions of the content never sent. The files
+	// whose changes or context match one are withheld whole, and the matches
+	// are replaced by Placeholder in the other texts of the pull request.
+	Patterns []string `mapstructure:"patterns"`
+
+	// Providers are the providers the repositories may be sent to, unless
+	// one of Repositories says otherwise. Every provider is allowed when it
+	// is empty.
+	Providers []string `mapstructure:"providers"`
+
+	Repositories []Repository `mapstructure:"repositories"`
+
+	paths    []*regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+// Repository sets the providers allowed for the repositories matching Name,
+// "owner/repo" or a pattern such as "owner/*". An empty list of providers
+// allows none, so nothing of the repository is sent.
+type Repository struct {
+	Name      string   `mapstructure:"name"`
+	Providers []string `mapstructure:"providers"`
+}
+
+// Load validates the policy and compiles its patterns.
+func Load(p Policy) (loaded Policy, err error) {
+	loaded = p
+	loaded.paths, loaded.patterns = nil, nil
+
+	for _, pattern := range p.Paths {
+		var re *regexp.Regexp
+
+		if re, err = profile.Glob(pattern); err != nil {
+			return Policy{}, fmt.Errorf("policy: invalid path %q: %w", pattern, err)
+		}
+
+		loaded.paths = append(loaded.paths, re)
+	}
+
+	for _, pattern := range p.Patterns {
+		var re *regexp.Regexp
+
+		if re, err = regexp.Compile(pattern); err != nil {
+			return Policy{}, fmt.Errorf("policy: invalid pattern %q: %w", pattern, err)
+		}
+
+		loaded.patterns = append(loaded.patterns, re)
+	}
+
+	for _, repository := range p.Repositories {
+		if _, err = path.Match(strings.ToLower(repository.Name), ""); err != nil || !strings.Contains(repository.Name, "/") {
+			return Policy{}, fmt.Errorf("policy: invalid repository %q, expected owner/repo", repository.Name)
+		}
+	}
+
+	return
+}
+
+// Allow returns an error when the content of the repository, "owner/repo",
+// may not be sent to provider. The first of Repositories matching the
+// repository applies, and Providers otherwise.
+func (p Policy) Allow(repository, provider string) error {
+	for _, r := range p.Repositories {
+		if matched, _ := path.Match(strings.ToLower(r.Name), strings.ToLower(repository)); matched {
+			if !contains(r.Providers, provider) {
+				return fmt.Errorf("the policy does not allow sending %s to %s", repository, provider)
+			}
+
+			return nil
+		}
+	}
+
+	if len(p.Providers) > 0 && !contains(p.Providers, provider) {
+		return fmt.Errorf("the policy does not allow sending %s to %s", repository, provider)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Withholds reports whether the file is never sent because of its path, and
+// returns the pattern of the path.
+func (p Policy) Withholds(filename string) (pattern string, withheld bool) {
+	for i, re := range p.paths {
+		if re.MatchString(filename) {
+			return p.Paths[i], true
+		}
+	}
+
+	return "", false
+}
+
+// withholdsFile reports whether file is never sent because of its path, or of
+// the path it had before being renamed.
+func (p Policy) withholdsFile(file content.File) (pattern string, withheld bool) {
+	if pattern, withheld = p.Withholds(file.Filename); withheld || file.PreviousFilename == "" {
+		return
+	}
+
+	return p.Withholds(file.PreviousFilename)
+}
+
+// Withheld is what the policy withheld from a pull request, for the audit
+// log. It names the patterns that matched, never the content.
+type Withheld struct {
+	// Files are the files withheld, with the reason.
+	Files map[string]string
+
+	// Texts are the texts of the pull request, other than the files, whose
+	// matches were replaced, with the reason.
+	Texts map[string]string
+
+	// Leaks are the secrets added by the commits of the pull request, which
+	// were replaced by placeholders with the other secrets.
+	Leaks []secrets.Leak
+}
+
+// Empty reports whether nothing was withheld by the policy. The secrets are
+// not part of the audit.
+func (w Withheld) Empty() bool {
+	return len(w.Files) == 0 && len(w.Texts) == 0
+}
+
+// Apply is what every pull request goes through before it is sent to a
+// model. It redacts the secrets, drops the files the policy withholds, in
+// every commit, and replaces the matches of the content patterns in every
+// other text that is rendered but the paths. The secrets are looked for
+// first, so the ones of the withheld files are leaks too.
+func (p Policy) Apply(pr *content.PullRequest) (withheld Withheld) {
+	withheld = Withheld{Files: make(map[string]string), Texts: make(map[string]string)}
+
+	withheld.Leaks = secrets.RedactPullRequest(pr)
+
+	for _, commit := range pr.Commits {
+		for _, file := range commit.Files {
+			if _, done := withheld.Files[file.Filename]; done {
+				continue
+			}
+
+			if pattern, found := p.withholdsFile(file); found {
+				withheld.Files[file.Filename] = fmt.Sprintf("path %q", pattern)
+			} else if pattern, found := p.match(fileText(file)); found {
+				withheld.Files[file.Filename] = fmt.Sprintf("content pattern %q", pattern)
+			}
+		}
+	}
+
+	for i := range pr.Commits {
+		commit := &pr.Commits[i]
+
+		var files []content.File
+		for _, file := range commit.Files {
+			if _, found := withheld.Files[file.Filename]; !found {
+				files = append(files, file)
+			}
+		}
+		commit.Files = files
+	}
+
+	// The changes of the files left do not match the content patterns
+	pr.EachText(func(name string, text *string) {
+		p.replace(text, name, withheld)
+	})
+
+	return
+}
+
+// fileText returns everything of a file sent to the model: its hunks, its
+// context and its comments.
+func fileText(file content.File) string {
+	var texts []string
+
+	for _, hunk := range file.Hunks {
+		texts = append(texts, hunk.Body)
+	}
+
+	for _, s := range file.Context {
+		texts = append(texts, s.Text)
+	}
+
+	for _, comment := range file.Comments {
+		texts = append(texts, comment.Body)
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// match returns the first content pattern matching text.
+func (p Policy) match(text string) (pattern string, found bool) {
+	for i, re := range p.patterns {
+		if re.MatchString(text) {
+			return p.Patterns[i], true
+		}
+	}
+
+	return "", false
+}
+
+// replace replaces the matches of the content patterns in text, and records
+// it in withheld under name.
+func (p Policy) replace(text *string, name string, withheld Withheld) {
+	for i, re := range p.patterns {
+		if re.MatchString(*text) {
+			*text = re.ReplaceAllLiteralString(*text, Placeholder)
+			withheld.Texts[name] = fmt.Sprintf("content pattern %q", p.Patterns[i])
+		}
+	}
+}
+
+// String returns the audit line of what was withheld.
+func (w Withheld) String() string {
+	var parts []string
+
+	for _, file := range sortedKeys(w.Files) {
+		parts = append(parts, fmt.Sprintf("file %s (%s)", file, w.Files[file]))
+	}
+
+	for _, text := range sortedKeys(w.Texts) {
+		parts = append(parts, fmt.Sprintf("%s (%s)", text, w.Texts[text]))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func sortedKeys(m map[string]string) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/profile"
+)
+
+// applyProfiles selects the review profiles from the labels of the pull
+// request, applies their model and temperature to cfg and drops the files
+// none of them reviews, as well as the files outside of cfg.Files.
+func applyProfiles(cfg *config.Config, pr *content.PullRequest) (selection profile.Selection) {
+	selection = profile.Select(cfg.Profiles, pr.Labels)
+
+	if model := selection.Model(); model != "" {
+		cfg.OpenaiModel = model
+	}
+
+	if temperature, found := selection.Temperature(); found {
+		cfg.Temperature = temperature
+	}
+
+	for i := range pr.Commits {
+		var files []content.File
+		for _, file := range pr.Commits[i].Files {
+			if selection.Matches(file.Filename) && requested(cfg.Files, file.Filename) {
+				files = append(files, file)
+			}
+		}
+		pr.Commits[i].Files = files
+	}
+
+	return
+}
+
+// requested reports whether a file is one of files or under one of their
+// directories. Every file is requested when files is empty.
+func requested(files []string, name string) bool {
+	if len(files) == 0 {
+		return true
+	}
+
+	for _, file := range files {
+		file = strings.Trim(file, "/")
+		if name == file || strings.HasPrefix(name, file+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterSeverity drops the findings below severity, unless it is empty.
+func filterSeverity(reviews github.Reviews, severity github.Severity) (filtered github.Reviews) {
+	if severity == "" {
+		return reviews
+	}
+
+	for _, review := range reviews.Review {
+		if review.Severity.AtLeast(severity) {
+			filtered.Review = append(filtered.Review, review)
+		}
+	}
+
+	return
+}
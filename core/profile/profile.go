@@ -0,0 +1,269 @@
+// Package profile selects the review profiles of a pull request from its
+// labels. A profile tunes the review: the instructions added to the prompt,
+// the model and its temperature, the lowest severity posted and the files
+// reviewed.
+package profile
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/prompt"
+)
+
+// LabelPrefix is the prefix of the labels of the built-in profiles, as in
+// "ai-review:security".
+const LabelPrefix = "ai-review:"
+
+type Profile struct {
+	Name string `mapstructure:"-"`
+
+	// Labels select the profile. They default to LabelPrefix followed by
+	// the name of the profile.
+	Labels []string `mapstructure:"labels"`
+
+	// Prompt is the name of a template of prompt.FOCUS or the path to a file
+	// with the instructions to add to the prompt.
+	Prompt string `mapstructure:"prompt"`
+
+	Model       string   `mapstructure:"model"`
+	Temperature *float32 `mapstructure:"temperature"`
+
+	// Severity is the lowest severity of the findings posted.
+	Severity github.Severity `mapstructure:"severity"`
+
+	// Include and Exclude are glob patterns of the files reviewed, where **
+	// matches any number of directories. Patterns without a slash match the
+	// base name of the files.
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+
+	focus string
+}
+
+// Defaults returns the built-in profiles, one per template of prompt.FOCUS.
+func Defaults() map[string]Profile {
+	return map[string]Profile{
+		"security": {Prompt: "security", Severity: github.SeverityLow},
+		"quick":    {Prompt: "quick", Severity: github.SeverityHigh, Exclude: []string{"*_test.go", "*.md"}},
+		"deep":     {Prompt: "deep", Temperature: float32Ptr(0.2)},
+	}
+}
+
+func float32Ptr(f float32) *float32 {
+	return &f
+}
+
+// Load completes and validates the profiles configured by name, reading
+// their prompt templates, and returns them sorted by name.
+func Load(profiles map[string]Profile) (loaded []Profile, err error) {
+	for name, p := range profiles {
+		p.Name = name
+
+		if len(p.Labels) == 0 {
+			p.Labels = []string{LabelPrefix + name}
+		}
+
+		if p.Severity != "" {
+			if p.Severity, err = github.ParseSeverity(string(p.Severity)); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+		}
+
+		for _, pattern := range append(p.Include, p.Exclude...) {
+			if _, err = Glob(pattern); err != nil {
+				return nil, fmt.Errorf("profile %s: invalid pattern %q: %w", name, pattern, err)
+			}
+		}
+
+		if p.focus, err = readPrompt(p.Prompt); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+
+		loaded = append(loaded, p)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Name < loaded[j].Name
+	})
+
+	return
+}
+
+// readPrompt returns the instructions of a built-in template or of a file.
+func readPrompt(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	if focus, found := prompt.FOCUS[name]; found {
+		return focus, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("prompt %q is neither a template nor a readable file: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Matches reports whether the profile reviews a file.
+func (p Profile) Matches(filename string) bool {
+	if len(p.Include) > 0 && !matchAny(p.Include, filename) {
+		return false
+	}
+
+	return !matchAny(p.Exclude, filename)
+}
+
+func matchAny(patterns []string, filename string) bool {
+	for _, pattern := range patterns {
+		if re, err := Glob(pattern); err == nil && re.MatchString(filename) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Glob compiles a glob pattern, where ** matches any number of directories.
+// Patterns without a slash match the base name of a path.
+func Glob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	if !strings.Contains(pattern, "/") {
+		b.WriteString("(^|/)")
+	} else {
+		b.WriteString("^")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// Selection is the profiles selected for a pull request, which are combined.
+type Selection []Profile
+
+// Select returns the profiles with a label of the pull request.
+func Select(profiles []Profile, labels []string) (selection Selection) {
+	for _, p := range profiles {
+		for _, label := range p.Labels {
+			if contains(labels, label) {
+				selection = append(selection, p)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name returns the names of the selected profiles.
+func (s Selection) Name() string {
+	var names []string
+	for _, p := range s {
+		names = append(names, p.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Focus returns the instructions of every selected profile.
+func (s Selection) Focus() string {
+	var focus []string
+	for _, p := range s {
+		if p.focus != "" {
+			focus = append(focus, p.focus)
+		}
+	}
+
+	return strings.Join(focus, "\n")
+}
+
+// Model returns the model of the first selected profile that sets one.
+func (s Selection) Model() string {
+	for _, p := range s {
+		if p.Model != "" {
+			return p.Model
+		}
+	}
+
+	return ""
+}
+
+// Temperature returns the temperature of the first selected profile that
+// sets one.
+func (s Selection) Temperature() (temperature float32, found bool) {
+	for _, p := range s {
+		if p.Temperature != nil {
+			return *p.Temperature, true
+		}
+	}
+
+	return 0, false
+}
+
+// Severity returns the lowest severity posted: the lowest of the selected
+// profiles, or none when one of them posts every finding.
+func (s Selection) Severity() (severity github.Severity) {
+	for i, p := range s {
+		if p.Severity == "" {
+			return ""
+		}
+
+		if i == 0 || !p.Severity.AtLeast(severity) {
+			severity = p.Severity
+		}
+	}
+
+	return
+}
+
+// Matches reports whether one of the selected profiles reviews a file. Every
+// file is reviewed when no profile is selected.
+func (s Selection) Matches(filename string) bool {
+	if len(s) == 0 {
+		return true
+	}
+
+	for _, p := range s {
+		if p.Matches(filename) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/prompt"
+)
+
+func Test_Matches(t *testing.T) {
+	var tests = []struct {
+		name     string
+		profile  Profile
+		filename string
+		expected bool
+	}{
+		{"no filters", Profile{}, "cmd/main.go", true},
+		{"base name pattern", Profile{Exclude: []string{"*_test.go"}}, "core/a_test.go", false},
+		{"double star", Profile{Include: []string{"core/**/*.go"}}, "core/github/client.go", true},
+		{"double star matches no directory", Profile{Include: []string{"core/**/*.go"}}, "core/core.go", true},
+		{"outside of the includes", Profile{Include: []string{"core/**"}}, "cmd/main.go", false},
+		{"single star stays in a directory", Profile{Include: []string{"core/*.go"}}, "core/github/client.go", false},
+		{"excluded from the includes", Profile{Include: []string{"**/*.go"}, Exclude: []string{"vendor/**"}}, "vendor/x/y.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if matches := tt.profile.Matches(tt.filename); matches != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, matches)
+			}
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "licenses.md")
+	if err := os.WriteFile(path, []byte("- Check the license headers.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name          string
+		profiles      map[string]Profile
+		errorExpected bool
+	}{
+		{"defaults", Defaults(), false},
+		{"prompt file", map[string]Profile{"licenses": {Prompt: path}}, false},
+		{"unknown prompt", map[string]Profile{"licenses": {Prompt: "licenses"}}, true},
+		{"invalid severity", map[string]Profile{"strict": {Severity: "urgent"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := Load(tt.profiles)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, p := range profiles {
+				if len(p.Labels) != 1 || p.Labels[0] != LabelPrefix+p.Name || p.focus == "" {
+					t.Fatalf("unexpected profile %+v", p)
+				}
+			}
+		})
+	}
+}
+
+func Test_Select(t *testing.T) {
+	profiles, err := Load(Defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name     string
+		labels   []string
+		expected string
+		severity github.Severity
+		focus    string
+	}{
+		{"no profile", []string{"bug"}, "", "", ""},
+		{"one profile", []string{"AI-Review:Security"}, "security", github.SeverityLow, prompt.FOCUS["security"]},
+		{"combined", []string{"ai-review:quick", "ai-review:security"}, "quick, security", github.SeverityLow, prompt.FOCUS["quick"] + "\n" + prompt.FOCUS["security"]},
+		{"combined with a profile without threshold", []string{"ai-review:quick", "ai-review:deep"}, "deep, quick", "", prompt.FOCUS["deep"] + "\n" + prompt.FOCUS["quick"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection := Select(profiles, tt.labels)
+
+			if selection.Name() != tt.expected || selection.Severity() != tt.severity || selection.Focus() != tt.focus {
+				t.Fatalf("expected %q with %q, got %q with %q", tt.expected, tt.severity, selection.Name(), selection.Severity())
+			}
+		})
+	}
+}
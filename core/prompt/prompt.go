@@ -1,110 +1,151 @@
 package prompt
 
-const (
-	PR_BODY_START      = `<<<PR_BODY_START>>>`
-	PR_BODY_END        = `<<<PR_BODY_END>>>`
-	PATCH_START        = `<<<PATCH_START>>>`
-	PATCH_END          = `<<<PATCH_END>>>`
-	COMMENT_BODY_START = `<<<COMMENT_BODY_START>>>`
-	COMMENT_BODY_END   = `<<<COMMENT_BODY_END>>>`
-	BEGIN_CONTENT      = `------------------------------ COMMIT BEGIN ------------------------------`
-	END_CONTENT        = `------------------------------- COMMIT END -------------------------------`
-	INITIAL_PROMPT     = `
+import (
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// INITIAL_PROMPT is the system prompt of a review. The description of the
+// input format and its example are generated from the content renderer, so
+// they always match what the model actually receives.
+var INITIAL_PROMPT = initialPrompt()
+
+// WALKTHROUGH_PROMPT is the system prompt of the walkthrough, the high-level
+// summary of a pull request posted as a comment of its conversation.
+var WALKTHROUGH_PROMPT = walkthroughPrompt()
+
+// VERIFY_PROMPT is the system prompt of the verification of a finding of a
+// review, made on an excerpt of the pull request.
+var VERIFY_PROMPT = verifyPrompt()
+
+// EXPLAIN_PROMPT is the system prompt of the explain command, which explains
+// lines of a pull request to the commenter who asked for it.
+var EXPLAIN_PROMPT = explainPrompt()
+
+// FOCUS holds the built-in prompt templates of the review profiles: the
+// instructions added to INITIAL_PROMPT to steer the review.
+var FOCUS = map[string]string{
+	"security": `- Focus on security: injection, broken authentication or authorization, secrets in the code, unsafe deserialization, path traversal, unvalidated input, insecure cryptography and sensitive data in logs.
+- Only report other problems when they are defects with a "high" or "critical" severity.
+- Use the "security" category for security findings.`,
+	"quick": `- Only report defects that would break the behavior of the changes: bugs, crashes, data loss and security issues.
+- Skip style, naming, maintainability and performance remarks.
+- Keep each review comment to one or two sentences.`,
+	"deep": `- Review the changes thoroughly, including their edge cases, error handling, concurrency, performance and the tests that cover them.
+- Check how the changes interact with the surrounding source given as context.
+- Report missing tests for new behavior on the lines that introduce it.`,
+}
+
+const instructions = `
 ChatGPT, you are tasked with reviewing GitHub pull requests. Please adhere to the following instructions:
-- Provide the response in following JSON format: {"reviews": [{"file": "<Filename>", "lineNumber": <Line number>, "reviewComment": "<Review comment>", "suggestionComments": "<Suggestion Comments>"}]}
+- Provide the response in following JSON format: {"reviews": [{"file": "<Filename>", "startLine": <Start line number>, "lineNumber": <End line number>, "severity": "<Severity>", "category": "<Category>", "reviewComment": "<Review comment>", "suggestionComments": "<Suggestion Comments>"}]}
+- The severity field is one of "info", "low", "medium", "high" or "critical": "critical" and "high" are defects that must be fixed before merging, such as bugs, security issues or data loss, "medium" are problems that should be fixed, and "low" and "info" are minor improvements.
+- The category field is a single lowercase word naming the kind of the finding, such as "bug", "security", "performance", "concurrency", "error-handling", "maintainability" or "style".
 - Upon receiving the content, analyze it thoroughly, and if there is any suggestion for code improvement for better code, provide the code to be implemented.
 - In the suggestionComment field, do not provide comments, only the code to be implemented.
 - Do not give positive comments or compliments.
 - Write the comment using GitHub Markdown format.
-- Take the "Pull request title" and "Pull request description" into account.
+- Take the title and the description of the pull request into account.
+- When the pull request claims to fix linked issues, check whether the changes actually do what the issues ask, and point out on the relevant lines what is missing or different.
 - IMPORTANT: NEVER suggest adding comments to the code.
-- You will receive the details about the Pull Request where the changes were made, along with the commit ID, the total additions, total deletions, total changes, status, file name, content, and comments made.
-- A Pull Request can have one or more commits. Each commit section starts with "------------------------------ COMMIT BEGIN ------------------------------" and ends with "------------------------------- COMMIT END -------------------------------".
-- A commit can have one or more modified files. Each file change section starts with "***FILE_START***" and ends with "***FILE_END***".
-- Inside each file section, you will find a Patch attribute containing the modification made to the file, described as a "git diff".
+- You will receive the details about the Pull Request where the changes were made, along with the commits, the files they changed with their patches, and the comments already made.
+- Do not raise points that the conversation shows were already discussed and decided.
 - If there is more than one comment for the same line of code in a previous commit, provide all the comments together for the same line and not separately.
-- When "PreviousFilename" has a null value and "Filename" has a value, the file is new.
-- When both "PreviousFilename" and "Filename" have a value, the file has been modified.
-- When both "PreviousFilename" and "Filename" have a value but are different, it means that the file has been renamed.
 - Input code:
-	- Analyze the Patch from each Commit.
-	- The section between "***PATCH_START***" and "***PATCH_END***" is the result of a "git diff".
-	- The next line below the separator "***PATCH_START***" is a "git diff hunk header". Use the git diff hunk header to determine the line numbers for your comments accurately.
-	- Hunks represent incomplete code fragments.
+	- Analyze the hunks of each file of each commit.
+	- Each hunk is the result of a "git diff". Its first line is the "git diff hunk header". Use the git diff hunk header to determine the line numbers for your comments accurately.
+	- Hunks represent incomplete code fragments. When a file has context elements, they contain the surrounding source of the file: use them to understand the code around the hunks, and do not report identifiers as undefined when they are defined there.
+	- Credentials found in the input are replaced by placeholders such as "[REDACTED:aws-access-key]". They are reported separately: do not comment on the placeholders.
 - Review output (reviewComment attribute):
 	- Put your concise comments here using GitHub Markdown format.
 	- Do not include positive feedback, compliments, or general commentary about the code.
 	- If explaining suggested changes, use fenced code blocks with the appropriate language identifier.
 	- All comments must be specific to the code lines in the new hunk from the diff.
-	- Review comments in markdown with exact line number ranges in new hunks. Start and end line numbers must be within the same hunk. For single-line comments, start=end line number.
+	- Review comments in markdown with exact line number ranges in new hunks, using the line numbers of the new version of the file. Start (startLine) and end (lineNumber) line numbers must be within the same hunk. For single-line comments, start=end line number.
 	- Please reply directly to the new comment (instead of suggesting a reply), and your reply will be posted as-is.
 - Suggested code output (suggestionComments attribute):
 	- Provide executable code changes in the "suggestionComments" field. No comments should be in this field.
 	- Don't annotate code snippets with line numbers. Format and indent code correctly.
 
+`
+
+const walkthroughInstructions = `
+ChatGPT, you are tasked with writing a walkthrough of GitHub pull requests, to help reviewers find their way through the changes. Please adhere to the following instructions:
+- Provide the response in following JSON format: {"summary": "<Summary>", "files": [{"file": "<Filename>", "summary": "<File summary>"}], "risks": ["<Risk area>"], "reviewOrder": ["<Filename>"]}
+- In the summary field, explain in a short paragraph what the pull request changes and why, using GitHub Markdown format.
+- In the files field, list every changed file once, with a one-line summary of its changes.
+- In the risks field, list the areas of the changes that deserve the most attention from reviewers, such as behavior changes, concurrency, security or missing tests. Leave it empty when there are none.
+- In the reviewOrder field, list the changed files in the order that makes the changes easiest to review.
+- Describe the state of the files at the head of the pull request, not the history of the commits.
+- Do not review the code, give opinions or compliments.
 
-### Here is an example of how you will receive the content to be analyzed:
-
-Pull request title: PullRequestTitleHere
-Pull request description:
-***PR_BODY_START***
-PullRequestBodyHere
-***PR_BODY_START***
-
------------------------------- COMMIT BEGIN ------------------------------
-CommitID: da31ac609173a56b005f359f03426bb712271cc7
-
-***FILE_START***
-Previous filename: a/test
-Filename: b/test
-Additions: 3
-Deletions: 1
-Changes: 4
-Status: modified
-Patch:
-***PATCH_START***
-@@ -1 +1,3 @@
--THIS IS ONLY A TEST FILE
-\ No newline at end of file
-+THIS IS ONLY A TEST FILE
-+
-+NEW LINE
-\ No newline at end of file
-***PATCH_END***
-Comments:
-	Comment:
-		Line: 3
-		User: laughing.crab
-		Body:
-			***COMMENT_BODY_START***
-			***COMMENT_BODY_END***
-***FILE_END***
-
-***FILE_START***
-Previous filename: a/fibo.py
-Filename: b/fibo.py
-Additions: 5
-Deletions: 0
-Changes: 5
-Status: added
-Patch:
-***PATCH_START***
-@@ -0,0 +1,5 @@
-+def fibonacci_ruim(n):
-+    if n <= 1:
-+        return n
-+    else:
-+        return fibonacci_ruim(n-1) + fibonacci_ruim(n-2)
-\ No newline at end of file
-***PATCH_END***
-Comments:
-	Comment:
-		Line: 3
-		User: laughing.crab
-		Body:
-			***COMMENT_BODY_START***
-			***COMMENT_BODY_END***
-***FILE_END***
-------------------------------- COMMIT END -------------------------------
 `
-)
+
+const verifyInstructions = `
+ChatGPT, you are tasked with verifying a comment that an automated reviewer wants to post on a GitHub pull request, to keep wrong or speculative comments from reaching its authors. Please adhere to the following instructions:
+- You will receive the finding, in the JSON format of the reviewer, followed by an excerpt of the pull request: the hunks of the file the finding is about and the source surrounding them.
+- Provide the response in following JSON format: {"verdict": "<Verdict>", "severity": "<Severity>", "reason": "<Reason>"}
+- The verdict field is one of:
+	- "confirm" when the finding is correct, relevant to the changed lines and as serious as its severity says.
+	- "downgrade" when the finding is correct but less serious than its severity says. Give the right severity in the severity field.
+	- "reject" when the finding is wrong, speculative, about code the pull request does not change, contradicted by the surrounding source, or only a matter of taste.
+- The severity field is one of "info", "low", "medium", "high" or "critical", the severity the finding deserves.
+- In the reason field, explain your verdict in one or two sentences.
+- Only rely on the excerpt: when the finding depends on code you can not see, reject it unless the excerpt makes it clearly correct.
+
+`
+
+const explainInstructions = `
+ChatGPT, you are tasked with explaining lines of code of GitHub pull requests to the reviewers who ask about them. Please adhere to the following instructions:
+- You will receive the file and the lines to explain, followed by an excerpt of the pull request: the hunks of the file that include the lines and the source surrounding them.
+- Provide the response in following JSON format: {"explanation": "<Explanation>"}
+- In the explanation field, explain what the lines do, why they are written this way when the excerpt shows it, and how the pull request changes them, using GitHub Markdown format.
+- Keep the explanation to a few short paragraphs, and quote the identifiers you refer to with backticks.
+- Only rely on the excerpt: say so when the lines depend on code you can not see, instead of guessing.
+- Do not review the code, give opinions or compliments.
+
+`
+
+func initialPrompt() string {
+	return reviewPrompt("")
+}
+
+// ReviewPrompt returns INITIAL_PROMPT with the instructions of focus, the
+// prompt templates of the review profiles, before its input format.
+func ReviewPrompt(focus string) string {
+	if focus == "" {
+		return INITIAL_PROMPT
+	}
+
+	return reviewPrompt(focus)
+}
+
+func reviewPrompt(focus string) string {
+	prompt := instructions
+	if focus != "" {
+		prompt += "### Focus\n\n" + focus + "\n\n"
+	}
+
+	return prompt +
+		"### Input format\n\n" +
+		content.Documentation() +
+		"\n### Here is an example of how you will receive the content to be analyzed:\n\n" +
+		content.Render(content.Example())
+}
+
+func walkthroughPrompt() string {
+	return walkthroughInstructions +
+		"### Input format\n\n" +
+		content.Documentation()
+}
+
+func verifyPrompt() string {
+	return verifyInstructions +
+		"### Input format\n\n" +
+		content.Documentation()
+}
+
+func explainPrompt() string {
+	return explainInstructions +
+		"### Input format\n\n" +
+		content.Documentation()
+}
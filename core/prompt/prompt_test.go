@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func Test_Prompts(t *testing.T) {
+	var tests = []struct {
+		name   string
+		prompt string
+	}{
+		{"initial_prompt.golden", INITIAL_PROMPT},
+		{"walkthrough_prompt.golden", WALKTHROUGH_PROMPT},
+		{"verify_prompt.golden", VERIFY_PROMPT},
+		{"explain_prompt.golden", EXPLAIN_PROMPT},
+		{"security_prompt.golden", ReviewPrompt(FOCUS["security"])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.name)
+
+			if *update {
+				if err := os.WriteFile(path, []byte(tt.prompt), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.prompt != string(want) {
+				t.Fatalf("%s does not match the prompt, run the tests with -update if the change is intended", path)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/codehost"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/failure"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/sarif"
+	"github.com/lucasmbaia/power-actions/core/verify"
+)
+
+// Output modes of a review.
+const (
+	// OutputReview posts the findings as a pull request review with inline
+	// comments.
+	OutputReview = "review"
+	// OutputCheck publishes the findings as a check run on the head SHA, with
+	// one annotation per finding.
+	OutputCheck = "check"
+	// OutputSARIF writes the findings as SARIF 2.1.0 to the file given after
+	// the prefix, as in "sarif=results.sarif".
+	OutputSARIF = "sarif="
+)
+
+// ValidateOutputs checks the output modes given to the review command.
+func ValidateOutputs(outputs []string) error {
+	for _, output := range outputs {
+		switch {
+		case output == OutputReview, output == OutputCheck:
+		case strings.HasPrefix(output, OutputSARIF) && len(output) > len(OutputSARIF):
+		default:
+			return failure.Errorf(failure.Config, "invalid output %q, expected %q, %q or %q", output, OutputReview, OutputCheck, OutputSARIF+"<path>")
+		}
+	}
+
+	return nil
+}
+
+// printDryRun writes the findings that would be published, and the verdicts
+// of their verification, as JSON.
+func printDryRun(w io.Writer, reviews github.Reviews, verdicts []verify.Verdict) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Findings []github.Review  `json:"findings"`
+		Verdicts []verify.Verdict `json:"verdicts,omitempty"`
+	}{reviews.Review, verdicts})
+}
+
+// publish sends the findings of prr to every configured output.
+func publish(cfg config.Config, prr github.PullRequestReviewRequest, pr content.PullRequest) (err error) {
+	for _, output := range cfg.Outputs {
+		switch {
+		case output == OutputReview:
+			err = failure.Wrap(failure.CodeHost, config.EnvSingletons.CodeHost.PullRequestReview(prr))
+		case strings.HasPrefix(output, OutputSARIF):
+			err = sarif.WriteFile(strings.TrimPrefix(output, OutputSARIF), prr.Reviews)
+		case output == OutputCheck:
+			checks, ok := config.EnvSingletons.CodeHost.(codehost.CheckRunner)
+			if !ok {
+				return failure.Errorf(failure.Config, "output %q is not supported on %s", OutputCheck, cfg.CodeHost)
+			}
+
+			err = failure.Wrap(failure.CodeHost, checks.CreateCheckRun(prr, github.CheckRunRequest{
+				HeadSHA:         pr.HeadSHA,
+				FailureSeverity: cfg.CheckFailureSeverity,
+				NeutralSeverity: cfg.CheckNeutralSeverity,
+			}))
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,224 @@
+// Package sarif exports review findings in the SARIF 2.1.0 format, so they
+// can be uploaded to code scanning.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+const (
+	schema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	version        = "2.1.0"
+	toolName       = "powerpr"
+	informationURI = "https://github.com/lucasmbaia/power-actions"
+
+	// defaultRule is the rule of findings without a category.
+	defaultRule = "general"
+)
+
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID               string         `json:"id"`
+	ShortDescription Message        `json:"shortDescription"`
+	Properties       RuleProperties `json:"properties"`
+}
+
+type RuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type Result struct {
+	RuleID     string           `json:"ruleId"`
+	RuleIndex  int              `json:"ruleIndex"`
+	Level      string           `json:"level"`
+	Message    Message          `json:"message"`
+	Locations  []Location       `json:"locations"`
+	Fixes      []Fix            `json:"fixes,omitempty"`
+	Properties ResultProperties `json:"properties"`
+}
+
+type ResultProperties struct {
+	Severity github.Severity `json:"severity,omitempty"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           Region           `json:"region"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type Region struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+type Fix struct {
+	Description     Message          `json:"description"`
+	ArtifactChanges []ArtifactChange `json:"artifactChanges"`
+}
+
+type ArtifactChange struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Replacements     []Replacement    `json:"replacements"`
+}
+
+type Replacement struct {
+	DeletedRegion   Region          `json:"deletedRegion"`
+	InsertedContent InsertedContent `json:"insertedContent"`
+}
+
+type InsertedContent struct {
+	Text string `json:"text"`
+}
+
+var nonRuleChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// New converts the findings of a review into a SARIF log with a single run.
+// Findings are grouped in rules by category, their level comes from their
+// severity, and their suggestion, if any, becomes a fix replacing the lines
+// of the finding.
+func New(reviews github.Reviews) Log {
+	var (
+		run = Run{
+			Tool: Tool{Driver: Driver{
+				Name:           toolName,
+				InformationURI: informationURI,
+				Rules:          []Rule{},
+			}},
+			Results: []Result{},
+		}
+		rules = make(map[string]int)
+	)
+
+	for _, review := range reviews.Review {
+		ruleID := RuleID(review.Category)
+
+		index, ok := rules[ruleID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[ruleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, Rule{
+				ID:               ruleID,
+				ShortDescription: Message{Text: ruleID},
+				Properties:       RuleProperties{Tags: []string{ruleID}},
+			})
+		}
+
+		start, end := review.Lines()
+		location := ArtifactLocation{URI: review.File, URIBaseID: "%SRCROOT%"}
+		region := Region{StartLine: start, EndLine: end}
+
+		result := Result{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     Level(review.Severity),
+			Message:   Message{Text: review.ReviewComment},
+			Locations: []Location{{PhysicalLocation: PhysicalLocation{
+				ArtifactLocation: location,
+				Region:           region,
+			}}},
+			Properties: ResultProperties{Severity: review.Severity},
+		}
+
+		if review.SuggestionComments != "" {
+			result.Fixes = []Fix{{
+				Description: Message{Text: "Apply the suggested change"},
+				ArtifactChanges: []ArtifactChange{{
+					ArtifactLocation: location,
+					Replacements: []Replacement{{
+						DeletedRegion:   region,
+						InsertedContent: InsertedContent{Text: strings.TrimSuffix(review.SuggestionComments, "\n") + "\n"},
+					}},
+				}},
+			}}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	return Log{Schema: schema, Version: version, Runs: []Run{run}}
+}
+
+// RuleID returns the SARIF rule identifier of a finding category.
+func RuleID(category string) string {
+	id := strings.Trim(nonRuleChars.ReplaceAllString(strings.ToLower(category), "-"), "-")
+	if id == "" {
+		return defaultRule
+	}
+
+	return id
+}
+
+// Level maps the severity of a finding to a SARIF result level.
+func Level(severity github.Severity) string {
+	switch {
+	case severity.AtLeast(github.SeverityHigh):
+		return "error"
+	case severity.AtLeast(github.SeverityMedium):
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// Write encodes the findings of a review as SARIF into w.
+func Write(w io.Writer, reviews github.Reviews) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(New(reviews))
+}
+
+// WriteFile writes the findings of a review as SARIF into the file at path.
+func WriteFile(path string, reviews github.Reviews) (err error) {
+	var file *os.File
+
+	if file, err = os.Create(path); err != nil {
+		return
+	}
+
+	if err = Write(file, reviews); err != nil {
+		file.Close()
+		return
+	}
+
+	return file.Close()
+}
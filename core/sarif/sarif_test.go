@@ -0,0 +1,59 @@
+package sarif
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func Test_Write(t *testing.T) {
+	const path = "testdata/findings.sarif"
+
+	var (
+		b       bytes.Buffer
+		reviews = github.Reviews{Review: []github.Review{{
+			File:               "core/core.go",
+			StartLine:          10,
+			LineNumber:         12,
+			Severity:           github.SeverityHigh,
+			Category:           "error-handling",
+			ReviewComment:      "The error is ignored.",
+			SuggestionComments: "if err != nil {\n\treturn err\n}",
+		}, {
+			File:          "README.md",
+			LineNumber:    3,
+			Severity:      github.SeverityLow,
+			ReviewComment: "Typo.",
+		}, {
+			File:          "core/chat.go",
+			LineNumber:    7,
+			Severity:      github.SeverityMedium,
+			Category:      "Error Handling",
+			ReviewComment: "Wrap the error.",
+		}}}
+	)
+
+	if err := Write(&b, reviews); err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Fatalf("%s does not match the SARIF output, run the tests with -update if the change is intended\ngot:\n%s", path, b.String())
+	}
+}
@@ -0,0 +1,178 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+// Leak is a secret added by a commit of a pull request, on the Line of File
+// in the new version of the file. Head is set when the commit is the last one
+// changing the file, so Line can be commented on in the pull request.
+type Leak struct {
+	Kind   string
+	File   string
+	Line   int
+	Commit string
+	Head   bool
+}
+
+// RedactPullRequest replaces the secrets of the content of pr with
+// placeholders, and returns the ones added by its commits. Secrets of the
+// removed and unchanged lines, and of every other text of the pull request
+// that is rendered, are redacted too, but they are not leaks of the pull
+// request.
+func RedactPullRequest(pr *content.PullRequest) (leaks []Leak) {
+	var (
+		seen = make(map[string]bool)
+		last = make(map[string]int)
+	)
+
+	for i, commit := range pr.Commits {
+		for _, file := range commit.Files {
+			last[file.Filename] = i
+		}
+	}
+
+	for i := range pr.Commits {
+		commit := &pr.Commits[i]
+
+		for j := range commit.Files {
+			file := &commit.Files[j]
+
+			for k := range file.Hunks {
+				for _, leak := range redactHunk(&file.Hunks[k]) {
+					leak.File = file.Filename
+					leak.Commit = commit.SHA
+					leak.Head = last[file.Filename] == i
+
+					key := fmt.Sprintf("%s:%s:%d:%s", leak.Commit, leak.File, leak.Line, leak.Kind)
+					if !seen[key] {
+						seen[key] = true
+						leaks = append(leaks, leak)
+					}
+				}
+			}
+		}
+	}
+
+	pr.EachText(func(_ string, text *string) {
+		*text = redactText(*text)
+	})
+
+	return
+}
+
+// redactHunk redacts the body of hunk and returns the secrets of its added
+// lines, with their line number in the new version of the file.
+func redactHunk(hunk *content.Hunk) (leaks []Leak) {
+	var found []LineMatches
+
+	if hunk.Body, found = Redact(hunk.Body); len(found) == 0 {
+		return
+	}
+
+	added := make(map[int]int)
+	line := hunk.NewStart
+
+	for i, text := range strings.Split(hunk.Body, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+"):
+			added[i] = line
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+
+	for _, f := range found {
+		number, ok := added[f.Line]
+		if !ok {
+			continue
+		}
+
+		for _, m := range f.Matches {
+			leaks = append(leaks, Leak{Kind: m.Kind, Line: number})
+		}
+	}
+
+	return
+}
+
+func redactText(text string) string {
+	redacted, _ := Redact(text)
+	return redacted
+}
+
+// Review returns the finding telling the author of the leak to rotate the
+// secret. It is critical: the secret is public to whoever can read the
+// repository, even once the line is removed.
+func (l Leak) Review() github.Review {
+	return github.Review{
+		File:       l.File,
+		StartLine:  l.Line,
+		LineNumber: l.Line,
+		Severity:   github.SeverityCritical,
+		Category:   "security",
+		ReviewComment: fmt.Sprintf("This line adds %s. It was replaced by a placeholder before the code was sent for review, "+
+			"but it is now in the history of the repository: revoke it and issue a new one, even if this line is removed. "+
+			"Load the new one from the environment or a secret manager instead of the code.", describe(l.Kind)),
+	}
+}
+
+// Reviews returns the findings of the leaks that can be commented on, one per
+// line and kind.
+func Reviews(leaks []Leak) (reviews []github.Review) {
+	seen := make(map[string]bool)
+
+	for _, leak := range leaks {
+		key := fmt.Sprintf("%s:%d:%s", leak.File, leak.Line, leak.Kind)
+		if !leak.Head || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		reviews = append(reviews, leak.Review())
+	}
+
+	return
+}
+
+// Summary returns the paragraph of the review listing the leaks of commits
+// that were followed by other changes of their file, whose lines the review
+// cannot comment on. It is empty when there are none.
+func Summary(leaks []Leak) string {
+	var lines []string
+
+	for _, leak := range leaks {
+		if leak.Head {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("- %s in `%s`, line %d of commit %s", describe(leak.Kind), leak.File, leak.Line, short(leak.Commit)))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "Earlier commits of this pull request add credentials. They stay in the history of the repository even once removed, so revoke them and issue new ones:\n" + strings.Join(lines, "\n")
+}
+
+func describe(kind string) string {
+	if description, ok := Descriptions[kind]; ok {
+		return description
+	}
+
+	return "a secret"
+}
+
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
@@ -0,0 +1,207 @@
+// Package secrets finds the credentials committed in pull requests, so they
+// are replaced by placeholders before the code is sent to the model and their
+// authors are told to rotate them.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Kinds of the secrets.
+const (
+	AWSAccessKey  = "aws-access-key"
+	AWSSecretKey  = "aws-secret-key"
+	GitHubToken   = "github-token"
+	GitLabToken   = "gitlab-token"
+	OpenAIKey     = "openai-key"
+	SlackToken    = "slack-token"
+	GoogleAPIKey  = "google-api-key"
+	StripeKey     = "stripe-key"
+	PrivateKey    = "private-key"
+	JWT           = "jwt"
+	GenericSecret = "generic-secret"
+)
+
+// Descriptions of the kinds, for the comments of the findings.
+var Descriptions = map[string]string{
+	AWSAccessKey:  "an AWS access key ID",
+	AWSSecretKey:  "an AWS secret access key",
+	GitHubToken:   "a GitHub token",
+	GitLabToken:   "a GitLab token",
+	OpenAIKey:     "an OpenAI API key",
+	SlackToken:    "a Slack token",
+	GoogleAPIKey:  "a Google API key",
+	StripeKey:     "a Stripe live key",
+	PrivateKey:    "a private key",
+	JWT:           "a JSON Web Token",
+	GenericSecret: "a secret assigned to a credential-like name",
+}
+
+// minEntropy is the lowest Shannon entropy, in bits per character, of the
+// values of generic secrets. Words, identifiers and placeholders such as
+// "changeme-in-production" are below it, random tokens above.
+const minEntropy = 3.5
+
+type detector struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// detectors only match high-confidence secrets: the ones with a known
+// format, and random-looking quoted values assigned to credential-like
+// names. When a detector has a "secret" group, only the group is the secret.
+var detectors = []detector{
+	{PrivateKey, regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{AWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{AWSSecretKey, regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?(?::=|[:=]|=>)\s*["'` + "`" + `]?(?P<secret>[A-Za-z0-9/+=]{40})\b`)},
+	{GitHubToken, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{GitLabToken, regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{OpenAIKey, regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_\-]{32,}\b`)},
+	{SlackToken, regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{GoogleAPIKey, regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{StripeKey, regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,}\b`)},
+	{JWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{GenericSecret, regexp.MustCompile(`(?i)[\w.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|private[_-]?key|credential)s?["']?\s*(?::=|[:=]|=>)\s*["'` + "`" + `](?P<secret>[^"'` + "`" + `\s]{16,})["'` + "`" + `]`)},
+}
+
+var privateKeyEnd = regexp.MustCompile(`-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+
+// Match is a secret found on a line, between the Start and End bytes.
+type Match struct {
+	Kind  string
+	Start int
+	End   int
+}
+
+// Placeholder returns the text that replaces a secret of kind.
+func Placeholder(kind string) string {
+	return fmt.Sprintf("[REDACTED:%s]", kind)
+}
+
+// Detect returns the secrets of a line, in order, without overlaps.
+func Detect(line string) (matches []Match) {
+	for _, d := range detectors {
+		group := d.re.SubexpIndex("secret")
+
+	next:
+		for _, loc := range d.re.FindAllStringSubmatchIndex(line, -1) {
+			m := Match{Kind: d.kind, Start: loc[0], End: loc[1]}
+			if group > 0 && loc[2*group] >= 0 {
+				m.Start, m.End = loc[2*group], loc[2*group+1]
+			}
+
+			if d.kind == GenericSecret && !random(line[m.Start:m.End]) {
+				continue
+			}
+
+			for _, other := range matches {
+				if m.Start < other.End && other.Start < m.End {
+					continue next
+				}
+			}
+
+			matches = append(matches, m)
+		}
+	}
+
+	// Detectors run one after the other, so their matches are sorted last
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].Start < matches[j-1].Start; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	return
+}
+
+// random reports whether a value looks like a generated secret: it mixes
+// letters and digits and its entropy is high enough.
+func random(value string) bool {
+	if !strings.ContainsAny(value, "0123456789") || strings.IndexFunc(value, isLetter) == -1 {
+		return false
+	}
+
+	return entropy(value) >= minEntropy
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// entropy returns the Shannon entropy of s, in bits per character.
+func entropy(s string) (bits float64) {
+	counts := make(map[rune]float64)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	length := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := count / length
+		bits -= p * math.Log2(p)
+	}
+
+	return
+}
+
+// LineMatches are the secrets of a line of a text.
+type LineMatches struct {
+	// Line is the index of the line in the text, from 0.
+	Line    int
+	Matches []Match
+}
+
+// Redact replaces the secrets of text with their placeholder, and returns
+// the secrets of each line. The lines of private keys, from their BEGIN line
+// to their END line, are replaced whole, as they only hold the key.
+func Redact(text string) (redacted string, found []LineMatches) {
+	var inPrivateKey bool
+
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		if inPrivateKey {
+			if privateKeyEnd.MatchString(line) {
+				inPrivateKey = false
+			}
+			lines[i] = keepDiffPrefix(line) + Placeholder(PrivateKey)
+			continue
+		}
+
+		matches := Detect(line)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			b.WriteString(line[last:m.Start])
+			b.WriteString(Placeholder(m.Kind))
+			last = m.End
+
+			if m.Kind == PrivateKey && !privateKeyEnd.MatchString(line[m.End:]) {
+				inPrivateKey = true
+			}
+		}
+		b.WriteString(line[last:])
+
+		lines[i] = b.String()
+		found = append(found, LineMatches{Line: i, Matches: matches})
+	}
+
+	return strings.Join(lines, "\n"), found
+}
+
+// keepDiffPrefix returns the "+", "-" or " " that starts the lines of a diff
+// hunk, so a redacted line keeps its place in the hunk.
+func keepDiffPrefix(line string) string {
+	if line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+		return line[:1]
+	}
+
+	return ""
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lucasmbaia/power-actions/core/command"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"go.uber.org/zap"
+)
+
+var (
+	errQueueFull = errors.New("the review queue is full")
+	errClosed    = errors.New("the server is shutting down")
+)
+
+// job is the pending review of a pull request. While a review is running,
+// later events of the same pull request only flag it to run once more, so a
+// burst of pushes results in at most one extra review.
+type job struct {
+	event   github.PullRequestEvent
+	running bool
+	rerun   bool
+}
+
+// pool runs reviews on a fixed number of workers, with at most one queued or
+// running review per pull request.
+type pool struct {
+	mu      sync.Mutex
+	closed  bool
+	queue   chan string
+	pending map[string]*job
+	wg      sync.WaitGroup
+	review  ReviewFunc
+	logger  *zap.Logger
+}
+
+func newPool(workers, queueSize int, review ReviewFunc, logger *zap.Logger) *pool {
+	p := &pool{
+		queue:   make(chan string, queueSize),
+		pending: make(map[string]*job),
+		review:  review,
+		logger:  logger,
+	}
+
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	return p
+}
+
+// jobKey identifies the job of event. The reviews of a pull request share its
+// key. A review command is also keyed by its author and its arguments, so it
+// is only merged with the same command of the same user, and one who can not
+// run commands does not replace the review already scheduled. Other commands
+// are keyed by their comment, so they are neither merged with the reviews of
+// the pull request nor with each other.
+func jobKey(event github.PullRequestEvent) string {
+	key := fmt.Sprintf("%s/%s#%d", event.Owner, event.Repo, event.PrNumber)
+
+	if cmd, found, _ := command.Parse(event.CommentBody); found {
+		if cmd.Name == command.Review {
+			key += " " + strings.Join(append([]string{event.Actor, cmd.Name}, cmd.Args...), " ")
+		} else {
+			key += fmt.Sprintf(" comment %d", event.CommentID)
+		}
+	}
+
+	return key
+}
+
+// enqueue schedules the review of the pull request of event. It reports
+// whether a review of the same pull request was already scheduled, in which
+// case the event replaces the one it was scheduled with.
+func (p *pool) enqueue(event github.PullRequestEvent) (merged bool, err error) {
+	key := jobKey(event)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false, errClosed
+	}
+
+	if j, ok := p.pending[key]; ok {
+		j.event = event
+		if j.running {
+			j.rerun = true
+		}
+		return true, nil
+	}
+
+	select {
+	case p.queue <- key:
+		p.pending[key] = &job{event: event}
+		return false, nil
+	default:
+		return false, errQueueFull
+	}
+}
+
+func (p *pool) work() {
+	defer p.wg.Done()
+
+	for key := range p.queue {
+		p.mu.Lock()
+		j := p.pending[key]
+		j.running = true
+		event := j.event
+		p.mu.Unlock()
+
+		p.logger.Info("Reviewing the pull request", zap.String("pullRequest", key), zap.String("event", event.Name))
+		if err := p.review(event); err != nil {
+			p.logger.Error("Error to review the pull request", zap.String("pullRequest", key), zap.Error(err))
+		}
+
+		p.mu.Lock()
+		j.running = false
+		if j.rerun && !p.closed {
+			j.rerun = false
+			select {
+			case p.queue <- key:
+			default:
+				p.logger.Warn("Dropping the new review of the pull request", zap.String("pullRequest", key), zap.Error(errQueueFull))
+				delete(p.pending, key)
+			}
+		} else {
+			delete(p.pending, key)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// close stops accepting reviews and waits for the queued and running ones to
+// finish, or for ctx to be done.
+func (p *pool) close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
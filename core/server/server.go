@@ -0,0 +1,253 @@
+// Package server receives GitHub webhooks and reviews the pull requests they
+// are about.
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/core/command"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"go.uber.org/zap"
+)
+
+// maxPayloadSize is the largest webhook payload GitHub delivers.
+const maxPayloadSize = 25 << 20
+
+// ReviewFunc reviews the pull request of an event, or runs the command of its
+// comment.
+type ReviewFunc func(event github.PullRequestEvent) error
+
+type Config struct {
+	Addr   string
+	Secret string
+
+	// Workers is the number of reviews run at the same time, and QueueSize
+	// the number of reviews waiting for a worker before new events are
+	// rejected.
+	Workers   int
+	QueueSize int
+
+	// TriggerLabel, when set, restricts the reviews to the pull requests
+	// that have this label.
+	TriggerLabel string
+
+	// ShutdownTimeout bounds the time given to running reviews to finish
+	// when the server stops.
+	ShutdownTimeout time.Duration
+
+	// Logger logs the reviews, and nothing is logged when it is nil.
+	Logger *zap.Logger
+}
+
+type Server struct {
+	cfg   Config
+	pool  *pool
+	ready atomic.Bool
+}
+
+// New returns a server that reviews pull requests with review. Its workers
+// start right away and are stopped by Serve, or Run, when it returns.
+func New(cfg Config, review ReviewFunc) (s *Server, err error) {
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("you must inform the webhook secret")
+	}
+
+	if cfg.Workers <= 0 || cfg.QueueSize <= 0 {
+		return nil, fmt.Errorf("workers and queue size need to be positive integers")
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+
+	return &Server{cfg: cfg, pool: newPool(cfg.Workers, cfg.QueueSize, review, cfg.Logger)}, nil
+}
+
+// Handler returns the routes of the server: the webhook endpoint, and the
+// health and readiness endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", s.handleWebhook)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
+
+// Run serves webhooks on cfg.Addr until ctx is done, then stops accepting
+// events and waits up to cfg.ShutdownTimeout for the running reviews.
+func (s *Server) Run(ctx context.Context) (err error) {
+	var listener net.Listener
+
+	if listener, err = net.Listen("tcp", s.cfg.Addr); err != nil {
+		return
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve is like Run, on an existing listener.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) (err error) {
+	var (
+		httpServer = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		serveErr   = make(chan error, 1)
+	)
+
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	s.ready.Store(true)
+	s.cfg.Logger.Info("Listening for webhooks", zap.Stringer("addr", listener.Addr()))
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+	}
+	s.ready.Store(false)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := httpServer.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+
+	if closeErr := s.pool.close(shutdownCtx); closeErr != nil && err == nil {
+		err = fmt.Errorf("waiting for the running reviews: %w", closeErr)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	return
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if !VerifySignature(s.cfg.Secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	name := r.Header.Get("X-GitHub-Event")
+	if name == "ping" {
+		fmt.Fprintln(w, "pong")
+		return
+	}
+
+	event, err := github.ParseEvent(name, payload)
+	if err != nil {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "ignored: %s\n", err.Error())
+		return
+	}
+
+	if reason := s.ignore(event); reason != "" {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "ignored: %s\n", reason)
+		return
+	}
+
+	merged, err := s.pool.enqueue(event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if merged {
+		fmt.Fprintln(w, "a review of this pull request is already scheduled")
+		return
+	}
+	fmt.Fprintln(w, "queued")
+}
+
+// ignore returns why an event does not trigger a review, or an empty string
+// when it does.
+func (s *Server) ignore(event github.PullRequestEvent) string {
+	switch event.Name {
+	case github.EventPullRequest, github.EventPullRequestTarget:
+		switch event.Action {
+		case "opened", "synchronize", "reopened":
+			if s.cfg.TriggerLabel != "" && !hasLabel(event.Labels, s.cfg.TriggerLabel) {
+				return fmt.Sprintf("the pull request has no %q label", s.cfg.TriggerLabel)
+			}
+		case "labeled":
+			if s.cfg.TriggerLabel != "" && event.Label != s.cfg.TriggerLabel {
+				return fmt.Sprintf("the label is not %q", s.cfg.TriggerLabel)
+			}
+		default:
+			return fmt.Sprintf("%s action %q", event.Name, event.Action)
+		}
+	case github.EventIssueComment, github.EventPullRequestReviewComment:
+		if event.Action != "created" {
+			return fmt.Sprintf("%s action %q", event.Name, event.Action)
+		}
+
+		if _, found, _ := command.Parse(event.CommentBody); !found {
+			return "the comment is not a command"
+		}
+	}
+
+	return ""
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header of a webhook, the
+// HMAC-SHA256 of its payload keyed with the webhook secret.
+func VerifySignature(secret string, payload []byte, header string) bool {
+	signature, found := strings.CutPrefix(header, "sha256=")
+	if !found {
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+const secret = "It's a Secret to Everybody"
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func pullRequestPayload(action, label string, number int) string {
+	return `{"action": "` + action + `", "label": {"name": "` + label + `"}, "pull_request": {"number": ` + strconv.Itoa(number) + `, "labels": [{"name": "` + label + `"}]}, "repository": {"name": "repo", "owner": {"login": "owner"}}}`
+}
+
+func Test_VerifySignature(t *testing.T) {
+	var tests = []struct {
+		name     string
+		header   string
+		expected bool
+	}{
+		{"valid signature", sign("payload"), true},
+		{"signature of another payload", sign("other"), false},
+		{"missing prefix", strings.TrimPrefix(sign("payload"), "sha256="), false},
+		{"invalid hex", "sha256=zz", false},
+		{"empty header", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if VerifySignature(secret, []byte("payload"), tt.header) != tt.expected {
+				t.Fatalf("expected %t", tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Webhook(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		mu      sync.Mutex
+		reviews []int
+	)
+
+	s, err := New(Config{Secret: secret, Workers: 1, QueueSize: 1, TriggerLabel: "ai-reviewer", ShutdownTimeout: time.Second}, func(event github.PullRequestEvent) error {
+		<-release
+		mu.Lock()
+		reviews = append(reviews, event.PrNumber)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(event, payload, signature string) int {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		r.Header.Set("X-GitHub-Event", event)
+		r.Header.Set("X-Hub-Signature-256", signature)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, r)
+		return w.Code
+	}
+
+	var tests = []struct {
+		name      string
+		event     string
+		payload   string
+		signature string
+		expected  int
+		picked    bool
+	}{
+		{"invalid signature", "pull_request", pullRequestPayload("opened", "ai-reviewer", 1), sign("other"), http.StatusUnauthorized, false},
+		{"ping", "ping", `{}`, sign(`{}`), http.StatusOK, false},
+		{"unsupported event", "push", `{}`, sign(`{}`), http.StatusAccepted, false},
+		{"first review is picked by the worker", "pull_request", pullRequestPayload("labeled", "ai-reviewer", 1), sign(pullRequestPayload("labeled", "ai-reviewer", 1)), http.StatusAccepted, true},
+		{"other label is ignored", "pull_request", pullRequestPayload("labeled", "bug", 2), sign(pullRequestPayload("labeled", "bug", 2)), http.StatusAccepted, false},
+		{"second review waits in the queue", "pull_request", pullRequestPayload("opened", "ai-reviewer", 2), sign(pullRequestPayload("opened", "ai-reviewer", 2)), http.StatusAccepted, false},
+		{"same pull request is merged", "pull_request", pullRequestPayload("synchronize", "ai-reviewer", 2), sign(pullRequestPayload("synchronize", "ai-reviewer", 2)), http.StatusAccepted, false},
+		{"full queue", "pull_request", pullRequestPayload("opened", "ai-reviewer", 3), sign(pullRequestPayload("opened", "ai-reviewer", 3)), http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := send(tt.event, tt.payload, tt.signature); code != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, code)
+			}
+
+			// Let the worker pick the review before filling the queue.
+			if tt.picked {
+				waitFor(t, func() bool {
+					s.pool.mu.Lock()
+					defer s.pool.mu.Unlock()
+					return len(s.pool.queue) == 0
+				})
+			}
+		})
+	}
+
+	close(release)
+	if err := s.pool.close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reviews) != 2 || reviews[0] != 1 || reviews[1] != 2 {
+		t.Fatalf("expected the reviews of #1 and #2, got %v", reviews)
+	}
+}
+
+func Test_Serve(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done    = make(chan error)
+	)
+
+	s, err := New(Config{Secret: secret, Workers: 1, QueueSize: 1, ShutdownTimeout: 5 * time.Second}, func(event github.PullRequestEvent) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	waitFor(t, func() bool {
+		resp, err := client.Get(url + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	})
+
+	payload := pullRequestPayload("opened", "", 1)
+	req, _ := http.NewRequest(http.MethodPost, url+"/webhook", strings.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Hub-Signature-256", sign(payload))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("the server stopped before the running review finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if s.ready.Load() {
+		t.Fatal("the server is still ready while shutting down")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	for i := 0; i < 200; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timed out")
+}
+
+func Test_jobKey(t *testing.T) {
+	var tests = []struct {
+		body     string
+		expected string
+	}{
+		{"", "owner/repo#1"},
+		{"/powerpr review", "owner/repo#1 ana review"},
+		{"/powerpr review a.go b.go", "owner/repo#1 ana review a.go b.go"},
+		{"/powerpr summarize", "owner/repo#1 comment 9"},
+		{"/powerpr ignore 1a2b3c4d", "owner/repo#1 comment 9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			event := github.PullRequestEvent{Owner: "owner", Repo: "repo", PrNumber: 1, CommentID: 9, CommentBody: tt.body, Actor: "ana"}
+			if key := jobKey(event); key != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package snippet
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// goDeclarations returns the line ranges of the top-level declarations that
+// enclose the hunks of a Go file, followed by the declarations of the types
+// of the same file that they use. Hunks outside of any declaration are
+// returned in uncovered, so the caller can fall back to a line window for
+// them. ok is false when the file can not be parsed.
+func goDeclarations(source string, hunks []content.Hunk) (ranges [][2]int, uncovered []content.Hunk, ok bool) {
+	var (
+		fset  = token.NewFileSet()
+		file  *ast.File
+		err   error
+		types = make(map[string][2]int)
+		used  = make(map[string]bool)
+		added = make(map[[2]int]bool)
+	)
+
+	if file, err = parser.ParseFile(fset, "", source, parser.ParseComments); err != nil {
+		return nil, nil, false
+	}
+
+	lines := func(from ast.Node, to ast.Node) [2]int {
+		return [2]int{fset.Position(from.Pos()).Line, fset.Position(to.End()).Line}
+	}
+
+	for _, decl := range file.Decls {
+		gen, isGen := decl.(*ast.GenDecl)
+		if !isGen || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			switch {
+			case !gen.Lparen.IsValid() && gen.Doc != nil:
+				types[ts.Name.Name] = lines(gen.Doc, gen)
+			case !gen.Lparen.IsValid():
+				types[ts.Name.Name] = lines(gen, gen)
+			case ts.Doc != nil:
+				types[ts.Name.Name] = lines(ts.Doc, ts)
+			default:
+				types[ts.Name.Name] = lines(ts, ts)
+			}
+		}
+	}
+
+	for _, hunk := range hunks {
+		start, end := hunk.NewStart, hunk.NewStart+hunk.NewLines-1
+		if end < start {
+			end = start
+		}
+
+		covered := false
+		for _, decl := range file.Decls {
+			r := lines(decl, decl)
+			if doc := declDoc(decl); doc != nil {
+				r[0] = fset.Position(doc.Pos()).Line
+			}
+
+			if r[1] < start || r[0] > end {
+				continue
+			}
+			covered = true
+
+			if !added[r] {
+				added[r] = true
+				ranges = append(ranges, r)
+
+				ast.Inspect(decl, func(n ast.Node) bool {
+					if ident, isIdent := n.(*ast.Ident); isIdent {
+						if _, isType := types[ident.Name]; isType {
+							used[ident.Name] = true
+						}
+					}
+					return true
+				})
+			}
+		}
+
+		if !covered {
+			uncovered = append(uncovered, hunk)
+		}
+	}
+
+	for name := range used {
+		if r := types[name]; !added[r] {
+			added[r] = true
+			ranges = append(ranges, r)
+		}
+	}
+
+	return ranges, uncovered, true
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package snippet
+
+import (
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+const goSource = `package sample
+
+import "fmt"
+
+// Point is a point in the plane.
+type Point struct {
+	X, Y int
+}
+
+type (
+	// Unused is not referenced by the changed code.
+	Unused struct{}
+
+	// Label names a point.
+	Label string
+)
+
+// String formats the point.
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+func other() {
+	fmt.Println("not changed")
+}
+
+// Describe labels a point.
+func Describe(p Point) Label {
+	var l Label
+
+	l = Label(p.String())
+
+	return l
+}
+
+// trailing comment
+`
+
+func Test_Context_Go(t *testing.T) {
+	var tests = []struct {
+		name     string
+		hunks    []content.Hunk
+		window   int
+		expected [][2]int
+	}{
+		{
+			"enclosing method and its receiver type",
+			[]content.Hunk{{NewStart: 20, NewLines: 1}},
+			0,
+			[][2]int{{5, 8}, {18, 21}},
+		},
+		{
+			"enclosing function and types from a grouped declaration",
+			[]content.Hunk{{NewStart: 32, NewLines: 1}},
+			0,
+			[][2]int{{5, 8}, {14, 15}, {27, 34}},
+		},
+		{
+			"hunk outside of declarations falls back to a window",
+			[]content.Hunk{{NewStart: 35, NewLines: 1}},
+			1,
+			[][2]int{{34, 36}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippets := Context("sample.go", goSource, tt.hunks, tt.window, 0)
+
+			if len(snippets) != len(tt.expected) {
+				t.Fatalf("expected %v, got %+v", tt.expected, snippets)
+			}
+
+			for i, s := range snippets {
+				if s.StartLine != tt.expected[i][0] || s.EndLine != tt.expected[i][1] {
+					t.Fatalf("expected lines %v, got %d-%d", tt.expected[i], s.StartLine, s.EndLine)
+				}
+			}
+		})
+	}
+}
+
+func Test_Context_GoParseError(t *testing.T) {
+	snippets := Context("broken.go", "package broken\n\nfunc {\n\nx\ny\nz\n", []content.Hunk{{NewStart: 5, NewLines: 1}}, 1, 0)
+
+	if len(snippets) != 1 || snippets[0].StartLine != 4 || snippets[0].EndLine != 6 {
+		t.Fatalf("expected a window fallback, got %+v", snippets)
+	}
+}
@@ -0,0 +1,98 @@
+// Package snippet selects the parts of a changed file that are sent to the
+// model as context for its hunks.
+package snippet
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+// Context returns the context of the hunks of a file, given the path and the
+// content of the file at the revision the hunks apply to. The whole file is
+// returned when it has no more than smallFile lines. Go files get the
+// declarations enclosing their hunks, along with the declarations of the
+// types those use; any other file, or hunk outside a Go declaration, gets
+// window lines above and below it. Overlapping ranges are merged.
+func Context(path, source string, hunks []content.Hunk, window, smallFile int) (snippets []content.Snippet) {
+	lines := splitLines(source)
+	if len(lines) == 0 {
+		return
+	}
+
+	if len(lines) <= smallFile {
+		return []content.Snippet{newSnippet(lines, 1, len(lines))}
+	}
+
+	var ranges [][2]int
+	if filepath.Ext(path) == ".go" {
+		if declarations, uncovered, ok := goDeclarations(source, hunks); ok {
+			ranges, hunks = declarations, uncovered
+		}
+	}
+
+	if window > 0 {
+		for _, hunk := range hunks {
+			start := hunk.NewStart - window
+			end := hunk.NewStart + hunk.NewLines - 1 + window
+
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+
+	for _, r := range merge(ranges, len(lines)) {
+		snippets = append(snippets, newSnippet(lines, r[0], r[1]))
+	}
+
+	return
+}
+
+// merge clamps the line ranges to the file and merges the ones that overlap
+// or touch each other. Ranges are expected sorted by their first line.
+func merge(ranges [][2]int, total int) (merged [][2]int) {
+	for _, r := range ranges {
+		if r[0] < 1 {
+			r[0] = 1
+		}
+		if r[1] > total {
+			r[1] = total
+		}
+		if r[0] > r[1] {
+			continue
+		}
+
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1]+1 {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return
+}
+
+func newSnippet(lines []string, start, end int) content.Snippet {
+	return content.Snippet{
+		StartLine: start,
+		EndLine:   end,
+		Text:      strings.Join(lines[start-1:end], "\n"),
+	}
+}
+
+func splitLines(source string) []string {
+	source = strings.TrimSuffix(source, "\n")
+	if source == "" {
+		return nil
+	}
+
+	return strings.Split(source, "\n")
+}
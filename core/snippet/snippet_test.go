@@ -0,0 +1,90 @@
+package snippet
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+)
+
+func numberedSource(n int) string {
+	var lines []string
+	for i := 1; i <= n; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func Test_Context(t *testing.T) {
+	var tests = []struct {
+		name      string
+		source    string
+		hunks     []content.Hunk
+		window    int
+		smallFile int
+		expected  [][2]int
+	}{
+		{
+			"small file is sent whole",
+			numberedSource(10),
+			[]content.Hunk{{NewStart: 5, NewLines: 1}},
+			2,
+			10,
+			[][2]int{{1, 10}},
+		},
+		{
+			"window around a single hunk",
+			numberedSource(100),
+			[]content.Hunk{{NewStart: 50, NewLines: 3}},
+			5,
+			10,
+			[][2]int{{45, 57}},
+		},
+		{
+			"windows are clamped to the file",
+			numberedSource(100),
+			[]content.Hunk{{NewStart: 2, NewLines: 2}, {NewStart: 98, NewLines: 3}},
+			5,
+			10,
+			[][2]int{{1, 8}, {93, 100}},
+		},
+		{
+			"overlapping windows are merged",
+			numberedSource(100),
+			[]content.Hunk{{NewStart: 20, NewLines: 2}, {NewStart: 28, NewLines: 1}},
+			5,
+			10,
+			[][2]int{{15, 33}},
+		},
+		{
+			"no window configured",
+			numberedSource(100),
+			[]content.Hunk{{NewStart: 20, NewLines: 2}},
+			0,
+			10,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippets := Context("file.txt", tt.source, tt.hunks, tt.window, tt.smallFile)
+
+			if len(snippets) != len(tt.expected) {
+				t.Fatalf("expected %d snippets, got %d", len(tt.expected), len(snippets))
+			}
+
+			for i, s := range snippets {
+				if s.StartLine != tt.expected[i][0] || s.EndLine != tt.expected[i][1] {
+					t.Fatalf("expected lines %v, got %d-%d", tt.expected[i], s.StartLine, s.EndLine)
+				}
+
+				if !strings.HasPrefix(s.Text, fmt.Sprintf("line %d\n", s.StartLine)) && s.StartLine != s.EndLine {
+					t.Fatalf("snippet does not start at line %d: %q", s.StartLine, s.Text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Package stats measures how the findings posted by powerpr are received, to
+// tell which categories, file types and models are worth their comments.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+// Unknown groups the findings whose comment does not record a category or a
+// model.
+const Unknown = "unknown"
+
+// Rates sums up the feedback of a group of findings.
+type Rates struct {
+	Group       string `json:"group"`
+	Findings    int    `json:"findings"`
+	ThumbsUp    int    `json:"thumbsUp"`
+	ThumbsDown  int    `json:"thumbsDown"`
+	Replies     int    `json:"replies"`
+	Resolved    int    `json:"resolved"`
+	Suggestions int    `json:"suggestions"`
+	Applied     int    `json:"applied"`
+	Accepted    int    `json:"accepted"`
+	// Acceptance is the share of the findings that were accepted.
+	Acceptance float64 `json:"acceptance"`
+}
+
+// Report holds the rates of the findings posted on a repository since a
+// given time, in total and by category, file type and model.
+type Report struct {
+	Repository string    `json:"repository"`
+	Since      time.Time `json:"since"`
+	Total      Rates     `json:"total"`
+	Categories []Rates   `json:"categories"`
+	FileTypes  []Rates   `json:"fileTypes"`
+	Models     []Rates   `json:"models"`
+}
+
+// Accepted reports whether the authors of a pull request accepted a finding:
+// its suggestion was applied, it got more 👍 than 👎, or its thread was
+// resolved without any 👎.
+func Accepted(f github.FindingComment) bool {
+	return f.Applied || f.ThumbsUp > f.ThumbsDown || (f.Resolved && f.ThumbsDown == 0)
+}
+
+// FileType returns the extension of a file, or its name when it has none,
+// such as Makefile.
+func FileType(file string) string {
+	if ext := path.Ext(file); ext != "" {
+		return strings.ToLower(ext)
+	}
+
+	return path.Base(file)
+}
+
+// NewReport computes the rates of findings.
+func NewReport(repository string, since time.Time, findings []github.FindingComment) (report Report) {
+	report = Report{Repository: repository, Since: since, Total: Rates{Group: "total"}}
+
+	var (
+		categories = make(map[string]*Rates)
+		fileTypes  = make(map[string]*Rates)
+		models     = make(map[string]*Rates)
+	)
+
+	for _, f := range findings {
+		report.Total.add(f)
+		group(categories, orUnknown(f.Category)).add(f)
+		group(fileTypes, FileType(f.File)).add(f)
+		group(models, orUnknown(f.Model)).add(f)
+	}
+
+	report.Total.rate()
+	report.Categories = sorted(categories)
+	report.FileTypes = sorted(fileTypes)
+	report.Models = sorted(models)
+
+	return
+}
+
+func (r *Rates) add(f github.FindingComment) {
+	r.Findings++
+	r.ThumbsUp += f.ThumbsUp
+	r.ThumbsDown += f.ThumbsDown
+	r.Replies += f.Replies
+
+	if f.Resolved {
+		r.Resolved++
+	}
+
+	if f.Suggested {
+		r.Suggestions++
+	}
+
+	if f.Applied {
+		r.Applied++
+	}
+
+	if Accepted(f) {
+		r.Accepted++
+	}
+}
+
+func (r *Rates) rate() {
+	if r.Findings > 0 {
+		r.Acceptance = float64(r.Accepted) / float64(r.Findings)
+	}
+}
+
+func group(groups map[string]*Rates, name string) *Rates {
+	if groups[name] == nil {
+		groups[name] = &Rates{Group: name}
+	}
+
+	return groups[name]
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return Unknown
+	}
+
+	return s
+}
+
+// sorted returns the groups with the most findings first.
+func sorted(groups map[string]*Rates) (rates []Rates) {
+	rates = []Rates{}
+	for _, r := range groups {
+		r.rate()
+		rates = append(rates, *r)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Findings != rates[j].Findings {
+			return rates[i].Findings > rates[j].Findings
+		}
+		return rates[i].Group < rates[j].Group
+	})
+
+	return
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// WriteTable writes the report as one table per grouping.
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Findings of %s since %s\n", r.Repository, r.Since.Format("2006-01-02"))
+
+	for _, section := range []struct {
+		title string
+		rates []Rates
+	}{
+		{"Total", []Rates{r.Total}},
+		{"Category", r.Categories},
+		{"File type", r.FileTypes},
+		{"Model", r.Models},
+	} {
+		fmt.Fprintf(tw, "\n%s\tFindings\t+1\t-1\tReplies\tResolved\tSuggestions\tApplied\tAccepted\n", section.title)
+		for _, rates := range section.rates {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\n",
+				rates.Group, rates.Findings, rates.ThumbsUp, rates.ThumbsDown, rates.Replies,
+				rates.Resolved, rates.Suggestions, rates.Applied, rates.Acceptance*100)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// ParseSince returns the time a period ago from now, given in days ("30d"),
+// weeks ("2w") or as a duration ("12h"), or the given date ("2024-05-01").
+func ParseSince(since string, now time.Time) (t time.Time, err error) {
+	if t, err = time.Parse("2006-01-02", since); err == nil {
+		return
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, found := strings.CutSuffix(since, suffix); found {
+			var n int
+			if n, err = strconv.Atoi(number); err != nil || n < 0 {
+				return t, fmt.Errorf("invalid period %q", since)
+			}
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+
+	var period time.Duration
+	if period, err = time.ParseDuration(since); err != nil || period < 0 {
+		return t, fmt.Errorf("invalid period %q, expected a number of days (30d), weeks (2w), a duration (12h) or a date (2006-01-02)", since)
+	}
+
+	return now.Add(-period), nil
+}
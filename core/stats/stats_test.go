@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+func Test_NewReport(t *testing.T) {
+	findings := []github.FindingComment{
+		{File: "a.go", Category: "bug", Model: "gpt-4o", Suggested: true, Applied: true},
+		{File: "b.go", Category: "bug", Model: "gpt-4o", ThumbsDown: 1, Resolved: true},
+		{File: "docs/README.md", Category: "style", ThumbsUp: 2, ThumbsDown: 1, Replies: 3},
+		{File: "Makefile", Resolved: true},
+	}
+
+	report := NewReport("owner/repo", time.Time{}, findings)
+
+	if total := report.Total; total.Findings != 4 || total.Accepted != 3 || total.Acceptance != 0.75 || total.Replies != 3 || total.Applied != 1 {
+		t.Fatalf("unexpected total %+v", total)
+	}
+
+	expected := []struct {
+		rates    []Rates
+		group    string
+		findings int
+		accepted int
+	}{
+		{report.Categories, "bug", 2, 1},
+		{report.Categories, Unknown, 1, 1},
+		{report.FileTypes, ".go", 2, 1},
+		{report.FileTypes, "Makefile", 1, 1},
+		{report.Models, Unknown, 2, 2},
+	}
+
+	for _, tt := range expected {
+		t.Run(tt.group, func(t *testing.T) {
+			for _, rates := range tt.rates {
+				if rates.Group == tt.group {
+					if rates.Findings != tt.findings || rates.Accepted != tt.accepted {
+						t.Fatalf("expected %d findings and %d accepted, got %+v", tt.findings, tt.accepted, rates)
+					}
+					return
+				}
+			}
+			t.Fatalf("no %s group in %+v", tt.group, tt.rates)
+		})
+	}
+
+	if report.Categories[0].Group != "bug" {
+		t.Fatalf("expected the groups with the most findings first, got %+v", report.Categories)
+	}
+
+	var table bytes.Buffer
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(table.String(), "75.0%") {
+		t.Fatalf("expected the total acceptance in the table, got\n%s", table.String())
+	}
+}
+
+func Test_ParseSince(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		since         string
+		expected      time.Time
+		errorExpected bool
+	}{
+		{"30d", now.AddDate(0, 0, -30), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"-3d", time.Time{}, true},
+		{"last month", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			since, err := ParseSince(tt.since, now)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err == nil && !since.Equal(tt.expected) {
+				t.Fatalf("expected %s, got %s", tt.expected, since)
+			}
+		})
+	}
+}
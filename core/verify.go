@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/prompt"
+	"github.com/lucasmbaia/power-actions/core/verify"
+	"go.uber.org/zap"
+)
+
+// verifyReviews asks the model, cfg.VerifyModel when it is set, to confirm,
+// downgrade or reject each finding given the code it is about, and returns
+// the findings to post with the verdicts of all of them. Only the confirmed
+// findings are posted: the ones that can not be verified, or get an unknown
+// verdict, are dropped with a warning.
+func verifyReviews(cfg config.Config, pr content.PullRequest, reviews github.Reviews) (verified github.Reviews, verdicts []verify.Verdict) {
+	var unconfirmed int
+
+	if cfg.VerifyModel != "" {
+		cfg.OpenaiModel = cfg.VerifyModel
+	}
+
+	for _, finding := range reviews.Review {
+		var answer verify.Answer
+
+		message, err := verify.Message(pr, finding)
+		if err == nil {
+			err = complete(cfg, prompt.VERIFY_PROMPT, message, &answer)
+		}
+
+		if err != nil {
+			config.EnvSingletons.Logger.Warn("Error to verify the finding", zap.String("file", finding.File), zap.Int("line", finding.LineNumber), zap.Error(err))
+			answer = verify.Answer{Verdict: verify.Unverified, Reason: err.Error()}
+		}
+
+		verdicts = append(verdicts, verify.Verdict{Finding: finding, Answer: answer})
+
+		if finding, posted := verify.Apply(finding, answer); posted {
+			verified.Review = append(verified.Review, finding)
+		} else if answer.Verdict != verify.Reject {
+			unconfirmed++
+		}
+	}
+
+	if unconfirmed > 0 {
+		config.EnvSingletons.Logger.Warn("Dropped the findings the verification could not confirm", zap.Int("count", unconfirmed))
+	}
+
+	return
+}
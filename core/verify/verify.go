@@ -0,0 +1,140 @@
+// Package verify prepares the second pass of a review, in which each finding
+// is sent back to the model with the code it is about, to be confirmed,
+// downgraded or rejected before it is posted.
+package verify
+
+import (
+	"encoding/json"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+// Verdicts of the verification of a finding.
+const (
+	Confirm   = "confirm"
+	Downgrade = "downgrade"
+	Reject    = "reject"
+	// Unverified findings could not be verified, and are not posted.
+	Unverified = "unverified"
+)
+
+// Answer is the answer of the model about a finding.
+type Answer struct {
+	Verdict  string          `json:"verdict"`
+	Severity github.Severity `json:"severity,omitempty"`
+	Reason   string          `json:"reason,omitempty"`
+}
+
+// Verdict records the verification of a finding, so the verdicts can be
+// reviewed to tune the prompts.
+type Verdict struct {
+	Finding github.Review `json:"finding"`
+	Answer
+}
+
+// Message returns the user message of the verification of a finding: the
+// finding in the JSON format of the review, followed by the excerpt of the
+// pull request it is about.
+func Message(pr content.PullRequest, finding github.Review) (message string, err error) {
+	var data []byte
+
+	if data, err = json.MarshalIndent(finding, "", "  "); err != nil {
+		return
+	}
+
+	return string(data) + "\n\n" + content.Render(Excerpt(pr, finding)), nil
+}
+
+// Excerpt returns the part of pr a finding is about: the most recent revision
+// of its file, reduced to the hunks and the context that include the lines of
+// the finding. The hunks and the context are kept whole when none of them
+// does.
+func Excerpt(pr content.PullRequest, finding github.Review) (excerpt content.PullRequest) {
+	excerpt = content.PullRequest{
+		Title:       pr.Title,
+		Description: pr.Description,
+	}
+
+	start, end := finding.Lines()
+
+	for i := len(pr.Commits) - 1; i >= 0; i-- {
+		for _, file := range pr.Commits[i].Files {
+			if file.Filename != finding.File {
+				continue
+			}
+
+			var (
+				hunks   []content.Hunk
+				context []content.Snippet
+			)
+
+			for _, hunk := range file.Hunks {
+				if overlaps(hunk.NewStart, hunk.NewStart+hunk.NewLines-1, start, end) {
+					hunks = append(hunks, hunk)
+				}
+			}
+
+			for _, snippet := range file.Context {
+				if overlaps(snippet.StartLine, snippet.EndLine, start, end) {
+					context = append(context, snippet)
+				}
+			}
+
+			if len(hunks) > 0 {
+				file.Hunks = hunks
+			}
+
+			if len(context) > 0 {
+				file.Context = context
+			}
+
+			excerpt.Commits = []content.Commit{{
+				SHA:     pr.Commits[i].SHA,
+				Message: pr.Commits[i].Message,
+				Files:   []content.File{file},
+			}}
+
+			return
+		}
+	}
+
+	return
+}
+
+func overlaps(start, end, otherStart, otherEnd int) bool {
+	return start <= otherEnd && otherStart <= end
+}
+
+// Apply returns the finding as it is posted after its verification, and
+// whether it is posted at all. Only the findings the model confirms are,
+// downgraded ones included: rejected and unverified findings, and the ones
+// with an unknown verdict, are not. Downgraded findings take the severity of
+// the answer, or the one below theirs when the answer gives none lower.
+func Apply(finding github.Review, answer Answer) (github.Review, bool) {
+	switch answer.Verdict {
+	case Confirm:
+		return finding, true
+	case Downgrade:
+		if severity, err := github.ParseSeverity(string(answer.Severity)); err == nil && !severity.AtLeast(finding.Severity) {
+			finding.Severity = severity
+		} else {
+			finding.Severity = lower(finding.Severity)
+		}
+
+		return finding, true
+	}
+
+	return finding, false
+}
+
+// lower returns the severity below s, or s when it is the lowest.
+func lower(s github.Severity) github.Severity {
+	for i := len(github.Severities) - 1; i > 0; i-- {
+		if s.AtLeast(github.Severities[i]) {
+			return github.Severities[i-1]
+		}
+	}
+
+	return github.SeverityInfo
+}
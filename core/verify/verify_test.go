@@ -0,0 +1,89 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lucasmbaia/power-actions/core/content"
+	"github.com/lucasmbaia/power-actions/core/github"
+)
+
+func Test_Apply(t *testing.T) {
+	var tests = []struct {
+		name     string
+		severity github.Severity
+		answer   Answer
+		expected github.Severity
+		posted   bool
+	}{
+		{"confirmed", github.SeverityHigh, Answer{Verdict: Confirm}, github.SeverityHigh, true},
+		{"rejected", github.SeverityHigh, Answer{Verdict: Reject}, github.SeverityHigh, false},
+		{"downgraded to the given severity", github.SeverityCritical, Answer{Verdict: Downgrade, Severity: github.SeverityLow}, github.SeverityLow, true},
+		{"downgraded without severity", github.SeverityHigh, Answer{Verdict: Downgrade}, github.SeverityMedium, true},
+		{"downgraded to a higher severity", github.SeverityLow, Answer{Verdict: Downgrade, Severity: github.SeverityHigh}, github.SeverityInfo, true},
+		{"downgraded from the lowest", github.SeverityInfo, Answer{Verdict: Downgrade}, github.SeverityInfo, true},
+		{"unverified", github.SeverityHigh, Answer{Verdict: Unverified}, github.SeverityHigh, false},
+		{"unknown verdict", github.SeverityHigh, Answer{Verdict: "maybe"}, github.SeverityHigh, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding, posted := Apply(github.Review{File: "a.go", Severity: tt.severity}, tt.answer)
+			if posted != tt.posted || finding.Severity != tt.expected {
+				t.Fatalf("expected %s posted %v, got %s posted %v", tt.expected, tt.posted, finding.Severity, posted)
+			}
+		})
+	}
+}
+
+func Test_Excerpt(t *testing.T) {
+	pr := content.PullRequest{
+		Title: "Fix the parser",
+		Commits: []content.Commit{
+			{SHA: "1", Files: []content.File{{Filename: "a.go", Hunks: []content.Hunk{{NewStart: 1, NewLines: 5, Header: "old"}}}}},
+			{SHA: "2", Files: []content.File{
+				{Filename: "b.go"},
+				{
+					Filename: "a.go",
+					Hunks:    []content.Hunk{{NewStart: 1, NewLines: 5, Header: "first"}, {NewStart: 20, NewLines: 4, Header: "second"}},
+					Context:  []content.Snippet{{StartLine: 1, EndLine: 10, Text: "top"}, {StartLine: 15, EndLine: 30, Text: "bottom"}},
+				},
+			}},
+		},
+		Conversation: []content.Post{{Body: "left out"}},
+	}
+
+	var tests = []struct {
+		name    string
+		finding github.Review
+		hunks   []string
+		context []string
+	}{
+		{"hunk and context of the lines", github.Review{File: "a.go", StartLine: 21, LineNumber: 22}, []string{"second"}, []string{"bottom"}},
+		{"whole file when no hunk includes the lines", github.Review{File: "a.go", LineNumber: 50}, []string{"first", "second"}, []string{"top", "bottom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excerpt := Excerpt(pr, tt.finding)
+
+			if excerpt.Title != pr.Title || len(excerpt.Conversation) != 0 || len(excerpt.Commits) != 1 || excerpt.Commits[0].SHA != "2" {
+				t.Fatalf("unexpected excerpt %+v", excerpt)
+			}
+
+			file := excerpt.Commits[0].Files[0]
+
+			var hunks, context []string
+			for _, hunk := range file.Hunks {
+				hunks = append(hunks, hunk.Header)
+			}
+			for _, snippet := range file.Context {
+				context = append(context, snippet.Text)
+			}
+
+			if strings.Join(hunks, ",") != strings.Join(tt.hunks, ",") || strings.Join(context, ",") != strings.Join(tt.context, ",") {
+				t.Fatalf("expected hunks %v and context %v, got %v and %v", tt.hunks, tt.context, hunks, context)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"github.com/lucasmbaia/power-actions/config"
+	"github.com/lucasmbaia/power-actions/core/github"
+	"github.com/lucasmbaia/power-actions/core/prompt"
+)
+
+// postWalkthrough asks the model for a walkthrough of the rendered pull
+// request and posts it as a comment, editing the one of a previous run. A
+// dry run of /summarize prints it instead; a dry-run review does not ask for
+// it, so its standard output only holds the JSON of the findings.
+func postWalkthrough(cfg config.Config, prr github.PullRequestReviewRequest, renderedPullRequest string) (err error) {
+	var walkthrough github.Walkthrough
+
+	if err = complete(cfg, prompt.WALKTHROUGH_PROMPT, renderedPullRequest, &walkthrough); err != nil {
+		return
+	}
+
+	return postComment(cfg, prr, github.WalkthroughMarker, walkthrough.Markdown())
+}
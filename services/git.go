@@ -3,6 +3,7 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
 )
@@ -13,6 +14,10 @@ type GitRepoInfo struct {
 	RepositoryName  string
 	RepositoryOwner string
 	OriginURL       string
+	Host            string
+	// RepositoryPath is the path of the repository on its host, which
+	// includes the subgroups of its namespace on GitLab.
+	RepositoryPath string
 }
 
 // getGitRepoInfo returns details about the current Git repository
@@ -40,30 +45,59 @@ func GetGitRepoInfo() (*GitRepoInfo, error) {
 		}
 	}
 
-	// Get the repository URL and parse the owner and repository name
+	// Get the repository URL and parse the host, owner and repository name
 	if output, err := exec.Command("git", "config", "--get", "remote.origin.url").Output(); err != nil {
 		return nil, fmt.Errorf("failed to get remote origin URL: %w", err)
 	} else {
 		info.OriginURL = strings.TrimSpace(string(output))
-		// Parse the owner and repository name from the URL
-		repoURL := strings.TrimSpace(string(output))
-		repoURL = strings.TrimSuffix(repoURL, ".git")
-		// Typical repo URLs are either SSH format (git@github.com:owner/repo) or HTTPS format (https://github.com/owner/repo)
-		if pos := strings.Index(repoURL, "@"); pos != -1 {
-			// SSH format
-			repoURL = repoURL[pos+1:]                       // Remove the 'git@'
-			repoURL = strings.Replace(repoURL, ":", "/", 1) // Replace ':' with '/'
+		if info.Host, info.RepositoryOwner, info.RepositoryName, err = ParseRemoteURL(info.OriginURL); err != nil {
+			return nil, err
 		}
-		if pos := strings.Index(repoURL, "://"); pos != -1 {
-			// HTTPS format
-			repoURL = repoURL[pos+3:] // Remove 'https://'
-		}
-		parts := strings.Split(repoURL, "/")
-		if len(parts) >= 3 {
-			info.RepositoryOwner = parts[1]
-			info.RepositoryName = parts[2]
+		if _, info.RepositoryPath, err = RemotePath(info.OriginURL); err != nil {
+			return nil, err
 		}
 	}
 
 	return info, nil
 }
+
+// ParseRemoteURL returns the host, the owner and the name of the repository of
+// a git remote URL. Both the SCP-like SSH format (git@host:owner/repo.git) and
+// URLs (https://host/owner/repo, ssh://git@host:22/owner/repo.git) are
+// supported, on github.com as well as on GitHub Enterprise Server.
+func ParseRemoteURL(remote string) (host, owner, repo string, err error) {
+	var path string
+
+	if host, path, err = RemotePath(remote); err != nil {
+		return "", "", "", err
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", "", "", fmt.Errorf("remote URL %q has no owner and repository", remote)
+	}
+
+	// Repositories are the last two segments, after any path prefix of the server
+	return host, parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// RemotePath returns the host of a git remote URL and the path of the
+// repository on it, without its .git suffix.
+func RemotePath(remote string) (host, path string, err error) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+
+	if strings.Contains(remote, "://") {
+		var u *url.URL
+		if u, err = url.Parse(remote); err != nil {
+			return "", "", fmt.Errorf("failed to parse remote URL: %w", err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at, colon := strings.Index(remote, "@"), strings.Index(remote, ":"); colon != -1 && at < colon {
+		// SCP-like SSH format
+		host, path = remote[at+1:colon], remote[colon+1:]
+	} else {
+		return "", "", fmt.Errorf("unsupported remote URL %q", remote)
+	}
+
+	return host, strings.Trim(path, "/"), nil
+}